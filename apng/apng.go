@@ -0,0 +1,232 @@
+// Package apng implements an encoder for animated PNG images, which unlike GIF images keep all colors and the alpha
+// channel of their frames. Viewers without APNG support show the first frame.
+//
+// The frames are written as 8-bit RGBA images covering the whole canvas, each replacing the previous one.
+package apng
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// APNG defines an animated PNG, similar to gif.GIF.
+type APNG struct {
+	// Image holds the frames, which must all have the same size.
+	Image []image.Image
+	// Delay holds the delay of each frame in 100ths of a second, at most 65535.
+	Delay []int
+	// LoopCount is the number of times the animation is played, zero meaning forever.
+	LoopCount int
+}
+
+// signature starts every PNG file.
+const signature = "\x89PNG\r\n\x1a\n"
+
+// encoder writes the chunks of an APNG.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+	// seq holds the next sequence number of the fcTL and fdAT chunks.
+	seq uint32
+}
+
+// Encode writes the APNG to the writer.
+func Encode(w io.Writer, a *APNG) error {
+	if len(a.Image) == 0 {
+		return errors.New("apng: no frames")
+	}
+	if len(a.Delay) != len(a.Image) {
+		return errors.New("apng: mismatched image and delay lengths")
+	}
+	if a.LoopCount < 0 {
+		return errors.New("apng: negative loop count")
+	}
+	size := a.Image[0].Bounds().Size()
+	if size.X <= 0 || size.Y <= 0 {
+		return errors.New("apng: empty frames")
+	}
+	for i, img := range a.Image {
+		if img.Bounds().Size() != size {
+			return fmt.Errorf("apng: frame %d is %v but the first frame is %v", i, img.Bounds().Size(), size)
+		}
+		// The delay is stored as the numerator of a fraction of 16 bits.
+		if a.Delay[i] < 0 || a.Delay[i] > math.MaxUint16 {
+			return fmt.Errorf("apng: delay %d of frame %d is out of range", a.Delay[i], i)
+		}
+	}
+
+	e := &encoder{w: bufio.NewWriter(w)}
+	if _, err := e.w.WriteString(signature); err != nil {
+		return err
+	}
+
+	var header [13]byte
+	binary.BigEndian.PutUint32(header[0:], uint32(size.X))
+	binary.BigEndian.PutUint32(header[4:], uint32(size.Y))
+	// Bit depth 8 of color type 6, which is RGBA, using the default compression, filtering and no interlacing.
+	header[8], header[9] = 8, 6
+	e.writeChunk("IHDR", header[:])
+
+	var control [8]byte
+	binary.BigEndian.PutUint32(control[0:], uint32(len(a.Image)))
+	binary.BigEndian.PutUint32(control[4:], uint32(a.LoopCount))
+	e.writeChunk("acTL", control[:])
+
+	for i, img := range a.Image {
+		e.writeFrameControl(size, a.Delay[i])
+		data, err := compress(img)
+		if err != nil {
+			return err
+		}
+		// The first frame is the default image read by viewers without APNG support.
+		if i == 0 {
+			e.writeChunk("IDAT", data)
+		} else {
+			e.writeChunk("fdAT", append(e.nextSeq(), data...))
+		}
+	}
+	e.writeChunk("IEND", nil)
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// nextSeq returns the encoded sequence number of the next chunk and increments it.
+func (e *encoder) nextSeq() []byte {
+	var seq [4]byte
+	binary.BigEndian.PutUint32(seq[:], e.seq)
+	e.seq++
+	return seq[:]
+}
+
+// writeFrameControl writes the fcTL chunk of a frame covering the whole canvas.
+func (e *encoder) writeFrameControl(size image.Point, delay int) {
+	var control [26]byte
+	copy(control[0:], e.nextSeq())
+	binary.BigEndian.PutUint32(control[4:], uint32(size.X))
+	binary.BigEndian.PutUint32(control[8:], uint32(size.Y))
+	// The offsets at 12 and 16 are zero.
+	binary.BigEndian.PutUint16(control[20:], uint16(delay))
+	binary.BigEndian.PutUint16(control[22:], 100)
+	// Keep the frame when disposing it, and replace the canvas by the next frame instead of blending, so transparent
+	// pixels stay transparent.
+	control[24], control[25] = 0, 0
+	e.writeChunk("fcTL", control[:])
+}
+
+// writeChunk writes a chunk of the provided type. Errors are kept in e.err, which stops all further writes.
+func (e *encoder) writeChunk(typ string, data []byte) {
+	if e.err != nil {
+		return
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[0:], uint32(len(data)))
+	copy(header[4:], typ)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	for _, b := range [][]byte{header[:], data, footer[:]} {
+		if _, e.err = e.w.Write(b); e.err != nil {
+			return
+		}
+	}
+}
+
+// compress returns the zlib compressed, filtered scanlines of the image as 8-bit RGBA.
+func compress(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	stride := bounds.Dx() * 4
+	prev, cur := make([]byte, stride), make([]byte, stride)
+	filtered := make([][]byte, 5)
+	for i := range filtered {
+		filtered[i] = make([]byte, stride+1)
+		filtered[i][0] = byte(i)
+	}
+
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i := (x - bounds.Min.X) * 4
+			cur[i], cur[i+1], cur[i+2], cur[i+3] = c.R, c.G, c.B, c.A
+		}
+		if _, err := z.Write(filter(filtered, cur, prev)); err != nil {
+			return nil, err
+		}
+		prev, cur = cur, prev
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// filter applies every filter type of PNG to the scanline and returns the filtered line with the smallest sum of
+// absolute differences, which usually compresses best. prev holds the previous, unfiltered scanline.
+func filter(filtered [][]byte, cur, prev []byte) []byte {
+	const bpp = 4
+	best, bestSum := 0, -1
+	for typ, out := range filtered {
+		line := out[1:]
+		sum := 0
+		for i := range cur {
+			var a, b, c byte
+			if i >= bpp {
+				a, c = cur[i-bpp], prev[i-bpp]
+			}
+			b = prev[i]
+			switch typ {
+			case 0:
+				line[i] = cur[i]
+			case 1:
+				line[i] = cur[i] - a
+			case 2:
+				line[i] = cur[i] - b
+			case 3:
+				line[i] = cur[i] - byte((int(a)+int(b))/2)
+			case 4:
+				line[i] = cur[i] - paeth(a, b, c)
+			}
+			sum += abs(int(int8(line[i])))
+		}
+		if bestSum < 0 || sum < bestSum {
+			best, bestSum = typ, sum
+		}
+	}
+	return filtered[best]
+}
+
+// paeth returns the Paeth predictor of the left, upper and upper left bytes.
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
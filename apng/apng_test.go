@@ -0,0 +1,142 @@
+package apng
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chunk is a chunk of a PNG file.
+type chunk struct {
+	typ  string
+	data []byte
+}
+
+// readChunks splits the PNG file into its chunks, verifying their checksums.
+func readChunks(t *testing.T, data []byte) []chunk {
+	assert.Equal(t, signature, string(data[:len(signature)]))
+	data = data[len(signature):]
+	var chunks []chunk
+	for len(data) > 0 {
+		length := binary.BigEndian.Uint32(data)
+		c := chunk{string(data[4:8]), data[8 : 8+length]}
+		assert.Equal(t, crc32.ChecksumIEEE(data[4:8+length]), binary.BigEndian.Uint32(data[8+length:]), c.typ)
+		chunks = append(chunks, c)
+		data = data[12+length:]
+	}
+	return chunks
+}
+
+// testFrames returns three frames of 3x2 pixels using colors and transparency GIF can't represent.
+func testFrames() []image.Image {
+	var frames []image.Image
+	for i := 0; i < 3; i++ {
+		img := image.NewNRGBA(image.Rect(10, 10, 13, 12))
+		for y := 10; y < 12; y++ {
+			for x := 10; x < 13; x++ {
+				img.SetNRGBA(x, y, color.NRGBA{uint8(x * 7), uint8(y * 13), uint8(i * 50), uint8(x*40 + i)})
+			}
+		}
+		frames = append(frames, img)
+	}
+	return frames
+}
+
+func Test_Encode(t *testing.T) {
+	frames := testFrames()
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, &APNG{Image: frames, Delay: []int{20, 20, 50}, LoopCount: 2}))
+
+	// Decoders without APNG support read the first frame.
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assertSameImage(t, frames[0], img)
+
+	chunks := readChunks(t, buf.Bytes())
+	var types []string
+	for _, c := range chunks {
+		types = append(types, c.typ)
+	}
+	assert.Equal(t, []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "fcTL", "fdAT", "IEND"}, types)
+	assert.Equal(t, []byte{0, 0, 0, 3, 0, 0, 0, 2}, chunks[1].data)
+
+	// The sequence numbers of fcTL and fdAT chunks count up from zero.
+	var seqs []uint32
+	for _, c := range chunks {
+		if c.typ == "fcTL" || c.typ == "fdAT" {
+			seqs = append(seqs, binary.BigEndian.Uint32(c.data))
+		}
+	}
+	assert.Equal(t, []uint32{0, 1, 2, 3, 4}, seqs)
+	assert.Equal(t, uint16(50), binary.BigEndian.Uint16(chunks[6].data[20:]))
+	assert.Equal(t, uint16(100), binary.BigEndian.Uint16(chunks[6].data[22:]))
+
+	// Every frame decodes to the original image when stored as the default image.
+	for i, c := range []chunk{chunks[5], chunks[7]} {
+		var frame bytes.Buffer
+		frame.WriteString(signature)
+		writeTestChunk(&frame, chunks[0])
+		writeTestChunk(&frame, chunk{"IDAT", c.data[4:]})
+		writeTestChunk(&frame, chunk{"IEND", nil})
+		img, err := png.Decode(&frame)
+		assert.NoError(t, err)
+		assertSameImage(t, frames[i+1], img)
+	}
+}
+
+func Test_EncodeErrors(t *testing.T) {
+	frames := testFrames()
+	assert.Error(t, Encode(io.Discard, &APNG{}))
+	assert.Error(t, Encode(io.Discard, &APNG{Image: frames, Delay: []int{1}}))
+	assert.EqualError(t, Encode(io.Discard, &APNG{Image: frames, Delay: []int{1, 65536, 1}}),
+		"apng: delay 65536 of frame 1 is out of range")
+	assert.EqualError(t, Encode(io.Discard, &APNG{Image: frames, Delay: []int{1, 1, -1}}),
+		"apng: delay -1 of frame 2 is out of range")
+	assert.NoError(t, Encode(io.Discard, &APNG{Image: frames, Delay: []int{0, 65535, 1}}))
+	frames[1] = image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	assert.EqualError(t, Encode(io.Discard, &APNG{Image: frames, Delay: []int{1, 1, 1}}),
+		"apng: frame 1 is (2,2) but the first frame is (3,2)")
+}
+
+func Test_compressFilters(t *testing.T) {
+	// A gradient is stored using the Sub filter, which turns it into constant differences.
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 1))
+	for x := 0; x < 64; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{uint8(x * 4), 0, 0, 255})
+	}
+	data, err := compress(img)
+	assert.NoError(t, err)
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	raw, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(1), raw[0])
+	assert.Len(t, raw, 1+64*4)
+}
+
+func writeTestChunk(w *bytes.Buffer, c chunk) {
+	binary.Write(w, binary.BigEndian, uint32(len(c.data)))
+	w.WriteString(c.typ)
+	w.Write(c.data)
+	binary.Write(w, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(c.typ), c.data...)))
+}
+
+func assertSameImage(t *testing.T, expected, actual image.Image) {
+	assert.Equal(t, expected.Bounds().Size(), actual.Bounds().Size())
+	offset := actual.Bounds().Min.Sub(expected.Bounds().Min)
+	for y := expected.Bounds().Min.Y; y < expected.Bounds().Max.Y; y++ {
+		for x := expected.Bounds().Min.X; x < expected.Bounds().Max.X; x++ {
+			e := color.NRGBAModel.Convert(expected.At(x, y))
+			a := color.NRGBAModel.Convert(actual.At(x+offset.X, y+offset.Y))
+			assert.Equal(t, e, a, "pixel at %d,%d", x, y)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hochbaum/vampire-survivors-tools/texturepacker"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("o", ".", "Specifies the directory to write the exported files to.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := expectArgs(flags.Args(), 2); err != nil {
+		return err
+	}
+
+	sheet, err := texturepacker.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	format := texturepacker.Format(flags.Arg(1))
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+	written, err := sheet.Export(format, *out)
+	if err != nil {
+		return fmt.Errorf("%w (formats: %v)", err, texturepacker.Formats)
+	}
+	for _, file := range written {
+		fmt.Println(file)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command defines a sub command of vs-atlas.
+type command struct {
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+// commands maps the names of all sub commands to their implementation.
+var commands = map[string]command{
+	"export":   {"export [-o <dir>] <sheet> <format>", "Exports a sprite sheet for another engine.", runExport},
+	"pack":     {"pack [flags] <images or directories>", "Packs images into a new sprite sheet.", runPack},
+	"replace":  {"replace [flags] <sheet> <directory>", "Replaces frames of a sprite sheet.", runReplace},
+	"validate": {"validate <sheet>", "Checks a sprite sheet against the images of its textures.", runValidate},
+}
+
+// expectArgs returns an error if the number of arguments does not match.
+func expectArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d arguments but got %d", n, len(args))
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(flag.CommandLine.Output(), "Usage: vs-atlas <command> [arguments]")
+	fmt.Fprintln(flag.CommandLine.Output(), "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %-40s %s\n", commands[name].usage, commands[name].description)
+	}
+	fmt.Fprintln(flag.CommandLine.Output(), "\nRun vs-atlas <command> -h for the flags of a command.")
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "vs-atlas %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
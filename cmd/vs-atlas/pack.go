@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hochbaum/vampire-survivors-tools/texturepacker"
+)
+
+func runPack(args []string) error {
+	flags := flag.NewFlagSet("pack", flag.ExitOnError)
+	out := flags.String("o", "atlas.json", "Specifies the sprite sheet to write, the textures are written next to it.")
+	packOptions := packFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("expected images or directories to pack")
+	}
+
+	var sprites []texturepacker.Sprite
+	for _, arg := range flags.Args() {
+		loaded, err := loadSprites(arg)
+		if err != nil {
+			return err
+		}
+		sprites = append(sprites, loaded...)
+	}
+
+	opts := packOptions()
+	opts.Name = strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+	atlas, err := texturepacker.Pack(sprites, opts)
+	if err != nil {
+		return err
+	}
+	if err := atlas.WriteFiles(*out); err != nil {
+		return err
+	}
+	fmt.Printf("Packed %d sprites into %d textures.\n", len(sprites), len(atlas.Sheet.Textures))
+	return reportStale(atlas, *out)
+}
+
+// packFlags defines the flags configuring the packer on the FlagSet. The returned function reads them once parsed.
+func packFlags(flags *flag.FlagSet) func() texturepacker.PackOptions {
+	maxSize := flags.Int("max", texturepacker.DefaultMaxSize, "Specifies the maximum width and height of a texture.")
+	padding := flags.Int("padding", 2, "Specifies the number of transparent pixels between sprites.")
+	trim := flags.Bool("trim", false, "Removes transparent borders of the sprites.")
+	rotate := flags.Bool("rotate", false, "Allows rotating sprites.")
+	return func() texturepacker.PackOptions {
+		return texturepacker.PackOptions{
+			MaxWidth:  *maxSize,
+			MaxHeight: *maxSize,
+			Padding:   *padding,
+			Trim:      *trim,
+			Rotate:    *rotate,
+		}
+	}
+}
+
+// loadSprites loads the PNG image at the provided path, or all PNG images inside of it if it is a directory. Sprites
+// are named by their path relative to the directory.
+func loadSprites(path string) ([]texturepacker.Sprite, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		sprite, err := loadSprite(path, filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		return []texturepacker.Sprite{sprite}, nil
+	}
+
+	var sprites []texturepacker.Sprite
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.EqualFold(filepath.Ext(file), ".png") {
+			return err
+		}
+		name, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		sprite, err := loadSprite(file, filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+		sprites = append(sprites, sprite)
+		return nil
+	})
+	return sprites, err
+}
+
+func loadSprite(path, name string) (texturepacker.Sprite, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return texturepacker.Sprite{}, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return texturepacker.Sprite{}, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+	return texturepacker.Sprite{Name: name, Image: img}, nil
+}
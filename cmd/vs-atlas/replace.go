@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hochbaum/vampire-survivors-tools/texturepacker"
+)
+
+func runReplace(args []string) error {
+	flags := flag.NewFlagSet("replace", flag.ExitOnError)
+	out := flags.String("o", "", "Specifies the sprite sheet to write, the original one is never overwritten.")
+	packOptions := packFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := expectArgs(flags.Args(), 2); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("expected the sprite sheet to write using -o")
+	}
+
+	sheet, err := texturepacker.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	sprites, err := loadSprites(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	// Repacked textures are named after the written sheet, just like by pack.
+	opts := packOptions()
+	opts.Name = strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+	atlas, report, err := texturepacker.Replace(sheet, sprites, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkOverwrite(flags.Arg(0), sheet, *out, atlas); err != nil {
+		return err
+	}
+	if err := atlas.WriteFiles(*out); err != nil {
+		return err
+	}
+
+	for _, name := range report.InPlace {
+		fmt.Printf("replaced: %s\n", name)
+	}
+	for _, name := range report.Repacked {
+		fmt.Printf("repacked: %s\n", name)
+	}
+	return reportStale(atlas, *out)
+}
+
+// checkOverwrite returns an error if writing the Atlas to out would overwrite the sprite sheet at the provided path or
+// any of its textures, so a failed or unwanted replacement never destroys the original.
+func checkOverwrite(path string, sheet *texturepacker.Sheet, out string, atlas *texturepacker.Atlas) error {
+	original := map[string]bool{absPath(path): true}
+	for _, texture := range sheet.Textures {
+		original[absPath(filepath.Join(filepath.Dir(path), texture.Image))] = true
+	}
+	if original[absPath(out)] {
+		return fmt.Errorf("%s would overwrite the original sprite sheet", out)
+	}
+	for _, texture := range atlas.Sheet.Textures {
+		if target := filepath.Join(filepath.Dir(out), texture.Image); original[absPath(target)] {
+			return fmt.Errorf("%s would overwrite a texture of the original sprite sheet", target)
+		}
+	}
+	return nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// reportStale prints the textures next to the written sprite sheet which it no longer references.
+func reportStale(atlas *texturepacker.Atlas, out string) error {
+	stale, err := atlas.StaleTextures(out)
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		fmt.Printf("stale: %s is no longer referenced by the sprite sheet\n", path)
+	}
+	return nil
+}
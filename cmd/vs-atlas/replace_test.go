@@ -0,0 +1,63 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hochbaum/vampire-survivors-tools/texturepacker"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeImage(t *testing.T, path string, img image.Image) {
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	assert.NoError(t, png.Encode(file, img))
+}
+
+func filledImage(size int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func Test_runReplaceNextToSheet(t *testing.T) {
+	dir := t.TempDir()
+	sheetPath := filepath.Join(dir, "characters.json")
+	atlas, err := texturepacker.Pack([]texturepacker.Sprite{
+		{Name: "a.png", Image: filledImage(4, color.Black)},
+		{Name: "b.png", Image: filledImage(3, color.Black)},
+	}, texturepacker.PackOptions{Name: "characters"})
+	assert.NoError(t, err)
+	assert.NoError(t, atlas.WriteFiles(sheetPath))
+	original, err := os.ReadFile(filepath.Join(dir, "characters.png"))
+	assert.NoError(t, err)
+
+	replacements := t.TempDir()
+	writeImage(t, filepath.Join(replacements, "a.png"), filledImage(4, color.White))
+
+	// The replacement fits into the existing area, so the textures are written in place, next to the original ones.
+	out := filepath.Join(dir, "mod.json")
+	assert.NoError(t, runReplace([]string{"-o", out, sheetPath, replacements}))
+
+	sheet, err := texturepacker.Open(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "mod.png", sheet.Textures[0].Image)
+	img, err := sheet.Frame("a.png")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{0xff, 0xff, 0xff, 0xff}, color.NRGBAModel.Convert(img.At(0, 0)))
+
+	unchanged, err := os.ReadFile(filepath.Join(dir, "characters.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, original, unchanged, "the original texture must not be overwritten")
+
+	assert.Error(t, runReplace([]string{"-o", sheetPath, sheetPath, replacements}))
+}
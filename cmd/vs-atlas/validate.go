@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hochbaum/vampire-survivors-tools/texturepacker"
+)
+
+func runValidate(args []string) error {
+	if err := expectArgs(args, 1); err != nil {
+		return err
+	}
+	sheet, err := texturepacker.Open(args[0])
+	if err != nil {
+		return err
+	}
+
+	var validationErr *texturepacker.ValidationError
+	if err := sheet.Validate(); errors.As(err, &validationErr) {
+		for _, problem := range validationErr.Problems {
+			fmt.Println(problem)
+		}
+		return fmt.Errorf("found %d problems", len(validationErr.Problems))
+	} else if err != nil {
+		return err
+	}
+	fmt.Println("Sprite sheet is valid.")
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+// defaultPath returns the location of the save file of the Electron builds of the game.
+func defaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "Vampire Survivors", "Local Storage", "leveldb")
+}
+
+func main() {
+	path := flag.String("path", defaultPath(), "Specifies the path to the LevelDB of the save file.")
+	addr := flag.String("addr", ":9337", "Specifies the address to listen on.")
+	flag.Parse()
+
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		// The save file is read on every scrape, without locking it, so the metrics are always up to date.
+		save, err := vampires.OpenSaveFileReadOnly(*path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		}
+		if err := writeMetrics(w, collect(save), openMetrics); err != nil {
+			fmt.Fprintf(os.Stderr, "could not write metrics: %v\n", err)
+		}
+	})
+
+	fmt.Printf("Exporting metrics of %s on %s/metrics\n", *path, *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		panic(err)
+	}
+}
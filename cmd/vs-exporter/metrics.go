@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+// sample defines a single value of a metric.
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// family defines a metric with all of its samples.
+type family struct {
+	name    string
+	help    string
+	counter bool
+	samples []sample
+}
+
+// collect turns the SaveFile into metric families.
+func collect(save *vampires.SaveFile) []family {
+	families := []family{
+		{name: "vampires_coins", help: "Coins which can be spent.", samples: single(save.Coins)},
+		{name: "vampires_lifetime_coins", help: "Coins collected in total.", counter: true,
+			samples: single(save.LifetimeCoins)},
+		{name: "vampires_lifetime_survived", help: "Time survived in total.", counter: true,
+			samples: single(float64(save.LifetimeSurvived))},
+		{name: "vampires_lifetime_heal", help: "Health healed in total.", counter: true,
+			samples: single(save.LifetimeHeal)},
+		{name: "vampires_kills", help: "Enemies killed, by enemy.", counter: true,
+			samples: perKey("enemy", save.KillCount)},
+		{name: "vampires_pickups", help: "Items picked up, by item.", counter: true,
+			samples: perKey("item", save.PickupCount)},
+		{name: "vampires_destroyed", help: "Objects destroyed, by object.", counter: true,
+			samples: perKey("object", save.DestroyedCount)},
+	}
+
+	unlocks := family{name: "vampires_unlocks", help: "Number of entries of the unlock and achievement lists, by list."}
+	for _, field := range save.Fields() {
+		if list, ok := field.Value.Interface().([]string); ok {
+			unlocks.samples = append(unlocks.samples,
+				sample{map[string]string{"list": field.Name}, float64(len(list))})
+		}
+	}
+	return append(families, unlocks)
+}
+
+func single(value float64) []sample {
+	return []sample{{value: value}}
+}
+
+// perKey creates a sample for every entry of the map, labeled with its key.
+func perKey(label string, m map[string]int32) []sample {
+	samples := make([]sample, 0, len(m))
+	for key, value := range m {
+		samples = append(samples, sample{map[string]string{label: key}, float64(value)})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].labels[label] < samples[j].labels[label]
+	})
+	return samples
+}
+
+// writeMetrics writes the families in the Prometheus text format or, if openMetrics is set, in the OpenMetrics format.
+// Both formats only differ in how counters are declared and in the terminating EOF marker of OpenMetrics.
+func writeMetrics(w io.Writer, families []family, openMetrics bool) error {
+	var b strings.Builder
+	for _, f := range families {
+		base := sanitizeName(f.name)
+		name, typ := base, "gauge"
+		if f.counter {
+			name, typ = base+"_total", "counter"
+		}
+		declared := name
+		if openMetrics {
+			declared = base
+		}
+
+		fmt.Fprintf(&b, "# HELP %s %s\n", declared, helpEscaper.Replace(f.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", declared, typ)
+		for _, s := range f.samples {
+			b.WriteString(name)
+			writeLabels(&b, s.labels)
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.value))
+			b.WriteByte('\n')
+		}
+	}
+	if openMetrics {
+		b.WriteString("# EOF\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeLabels writes the labels in the form `{name="value",...}`, sorted by their names.
+func writeLabels(b *strings.Builder, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(b, "%s=\"%s\"", sanitizeName(name), labelEscaper.Replace(labels[name]))
+	}
+	b.WriteByte('}')
+}
+
+// labelEscaper escapes label values as required by both formats.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes the help texts as required by the Prometheus text format.
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// sanitizeName replaces the characters which are not allowed in metric and label names with underscores, and prefixes
+// names starting with a digit with one.
+func sanitizeName(name string) string {
+	sanitized := []byte(name)
+	for i, c := range sanitized {
+		valid := c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9'
+		if !valid && i == 0 && c >= '0' && c <= '9' {
+			return sanitizeName("_" + name)
+		}
+		if !valid {
+			sanitized[i] = '_'
+		}
+	}
+	return string(sanitized)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFamilies = []family{
+	{name: "vampires_coins", help: "Coins which can be spent.", samples: single(1337.5)},
+	{name: "vampires_kills", help: "Enemies killed,\nby enemy \\ type.", counter: true, samples: []sample{
+		{map[string]string{"enemy": `BAT"1`}, 42},
+		{map[string]string{"enemy": "back\\slash\nnewline"}, math.Inf(1)},
+	}},
+	{name: "1vampires-odd.name", help: "Odd.", samples: []sample{
+		{map[string]string{"list": "a", "2nd-label": "b"}, math.NaN()},
+	}},
+}
+
+func Test_writeMetrics(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, writeMetrics(&b, testFamilies, false))
+	assert.Equal(t, `# HELP vampires_coins Coins which can be spent.
+# TYPE vampires_coins gauge
+vampires_coins 1337.5
+# HELP vampires_kills_total Enemies killed,\nby enemy \\ type.
+# TYPE vampires_kills_total counter
+vampires_kills_total{enemy="BAT\"1"} 42
+vampires_kills_total{enemy="back\\slash\nnewline"} +Inf
+# HELP _1vampires_odd_name Odd.
+# TYPE _1vampires_odd_name gauge
+_1vampires_odd_name{_2nd_label="b",list="a"} NaN
+`, b.String())
+}
+
+func Test_writeMetricsOpenMetrics(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, writeMetrics(&b, testFamilies, true))
+	assert.Equal(t, `# HELP vampires_coins Coins which can be spent.
+# TYPE vampires_coins gauge
+vampires_coins 1337.5
+# HELP vampires_kills Enemies killed,\nby enemy \\ type.
+# TYPE vampires_kills counter
+vampires_kills_total{enemy="BAT\"1"} 42
+vampires_kills_total{enemy="back\\slash\nnewline"} +Inf
+# HELP _1vampires_odd_name Odd.
+# TYPE _1vampires_odd_name gauge
+_1vampires_odd_name{_2nd_label="b",list="a"} NaN
+# EOF
+`, b.String())
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+func main() {
+	out := flag.String("o", "SaveData", "Specifies the path of the Unity save file to write. "+
+		"If it already exists, it is used as template and only known fields are migrated.")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: vs-migrate [-o SaveData] <path to the legacy LevelDB save>")
+		os.Exit(1)
+	}
+
+	report, err := vampires.MigrateToUnity(flag.Arg(0), *out)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Migrated %d fields to %s.\n", len(report.Mapped), *out)
+	for _, field := range report.Unmapped {
+		fmt.Printf("Could not migrate %s: %s\n", field.Key, field.Reason)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+}
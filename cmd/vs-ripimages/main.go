@@ -2,46 +2,34 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	"image/gif"
 	"image/png"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/andybons/gogif"
+	"github.com/hochbaum/vampire-survivors-tools/apng"
 	"github.com/hochbaum/vampire-survivors-tools/texturepacker"
 	"github.com/nfnt/resize"
 )
 
-var gifNameExp1 = regexp.MustCompile(`^(.*)_(\d*)\.png`)
-var gifNameExp2 = regexp.MustCompile(`(.*)(\d)\.png`)
-
-type cropper interface {
-	SubImage(r image.Rectangle) image.Image
-}
-
-func cropFrames(filePath string, size int, texture texturepacker.PackedTexture) (map[string]image.Image, error) {
-	directory := filepath.Dir(filePath)
-	imgFile, err := os.Open(filepath.Join(directory, texture.Image))
-	if err != nil {
-		panic(err)
-	}
-	defer imgFile.Close()
-
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		return nil, err
-	}
-
+func cropFrames(sheet *texturepacker.Sheet, size int) (map[string]image.Image, error) {
 	images := make(map[string]image.Image)
-	for _, frame := range texture.Frames {
-		cropped := img.(cropper).SubImage(frame.Frame.Rect())
-		if cropped.Bounds().Dx() <= 6 && cropped.Bounds().Dy() <= 6 {
+	for _, frame := range sheet.Frames() {
+		if region := frame.Region(); region.Dx() <= 6 && region.Dy() <= 6 {
 			continue
 		}
+		cropped, err := frame.Image()
+		if err != nil {
+			return nil, err
+		}
 		cropped = resize.Resize(
 			uint(resizeInt(frame.SourceSize.Width, size)),
 			uint(resizeInt(frame.SourceSize.Height, size)),
@@ -56,7 +44,7 @@ func resizeInt(i, resizePercent int) int {
 }
 
 func writeImage(path string, img image.Image) error {
-	file, err := os.Create(path)
+	file, err := createFile(path)
 	if err != nil {
 		return err
 	}
@@ -73,39 +61,64 @@ func writeImages(path string, images map[string]image.Image) error {
 	return nil
 }
 
-// sortImages sorts given images according to their position in the gif
-func sortImages(images map[string]image.Image) (map[string][]image.Image, error) {
-	gifs := make(map[string][]image.Image)
-	for name, img := range images {
-		parts := gifNameExp1.FindStringSubmatch(name)
-		// Checking if image is part of a gif
-		if len(parts) != 3 {
-			parts = gifNameExp2.FindStringSubmatch(name)
-			if len(parts) != 3 {
-				continue
+// animationImages returns the images of the frames of each animation of the sheet, skipping frames left out by
+// cropFrames. Gaps within the animations and frames sharing an index are reported, as these are usually misnamed
+// frames.
+func animationImages(sheet *texturepacker.Sheet, images map[string]image.Image) map[string][]image.Image {
+	animations := make(map[string][]image.Image)
+	for _, anim := range texturepacker.Animations(sheet) {
+		if len(anim.Missing) > 0 {
+			missing := make([]string, len(anim.Missing))
+			for i, r := range anim.Missing {
+				missing[i] = r.String()
 			}
+			fmt.Fprintf(os.Stderr, "animation %s is missing frames %s\n", anim.Name, strings.Join(missing, ", "))
 		}
-		gifName, gifOrderRaw := parts[1], parts[2]
-		gifOrder, _ := strconv.Atoi(gifOrderRaw)
-		// Allocating enough memory for 128 frames
-		if gifs[gifName] == nil {
-			gifs[gifName] = make([]image.Image, 128)
+		for _, index := range anim.Duplicates {
+			var names []string
+			for i, frame := range anim.Frames {
+				if anim.Indices[i] == index {
+					names = append(names, frame.FileName)
+				}
+			}
+			fmt.Fprintf(os.Stderr, "animation %s has several frames with index %d: %s\n", anim.Name, index,
+				strings.Join(names, ", "))
+		}
+		for _, frame := range anim.Frames {
+			if img, ok := images[frame.FileName]; ok {
+				animations[anim.Name] = append(animations[anim.Name], img)
+			}
 		}
-		gifs[gifName][gifOrder] = img
 	}
-	return gifs, nil
+	return animations
+}
+
+// animationFormat defines how the animations are written for a value of the -format flag.
+type animationFormat struct {
+	// ext is appended to the name of the animation to get the path written to. It differs for every format, so the
+	// files don't overwrite each other or the single images, which are written as `<name>.png`.
+	ext   string
+	write func(path string, frames []*image.NRGBA) error
 }
 
-// normalizeImages normalizes images according to the largest image, creates palette
-func normalizeImages(images []image.Image) ([]*image.Paletted, error) {
+var animationFormats = map[string]animationFormat{
+	"gif":  {".gif", writeGif},
+	"apng": {".apng.png", writeAPNG},
+	"strip-h": {".strip-h.png", func(path string, frames []*image.NRGBA) error {
+		return writeStrip(path, frames, image.Pt(1, 0))
+	}},
+	"strip-v": {".strip-v.png", func(path string, frames []*image.NRGBA) error {
+		return writeStrip(path, frames, image.Pt(0, 1))
+	}},
+	"png-seq": {".frames", writePNGSequence},
+}
+
+// fitImages draws the images onto transparent canvases of the size of the largest image, aligned to their bottom right
+// corner.
+func fitImages(images []image.Image) []*image.NRGBA {
 	w, h := 0, 0
-	quantizer := gogif.MedianCutQuantizer{NumColor: 64}
-	normalized := make([]*image.Paletted, 0)
 	// Finding biggest image bounds
 	for _, img := range images {
-		if img == nil {
-			continue
-		}
 		dx, dy := img.Bounds().Dx(), img.Bounds().Dy()
 		if dx > w {
 			w = dx
@@ -115,22 +128,103 @@ func normalizeImages(images []image.Image) ([]*image.Paletted, error) {
 		}
 	}
 	bounds := image.Rect(0, 0, w, h)
+	fitted := make([]*image.NRGBA, 0, len(images))
 	for _, img := range images {
-		if img == nil {
-			continue
-		}
 		srcBounds := img.Bounds()
-		fixedSize := image.NewRGBA(bounds)
+		fixedSize := image.NewNRGBA(bounds)
 		// Fitting image into max bounds
 		r := image.Rectangle{
 			image.Pt(bounds.Dx()-srcBounds.Dx(), bounds.Dy()-srcBounds.Dy()),
 			image.Pt(bounds.Dx(), bounds.Dy())}
-		draw.Draw(fixedSize, r, img, image.Point{}, draw.Src)
-		palettedImage := image.NewPaletted(bounds, nil)
-		quantizer.Quantize(palettedImage, bounds, fixedSize, image.Point{})
+		draw.Draw(fixedSize, r, img, srcBounds.Min, draw.Src)
+		fitted = append(fitted, fixedSize)
+	}
+	return fitted
+}
+
+// minGifAlpha is the alpha value from which on pixels are opaque in GIF images, which only support fully transparent
+// pixels.
+const minGifAlpha = 128
+
+// normalizeImages converts the images into paletted images sharing the palette created by gifPalette.
+func normalizeImages(images []*image.NRGBA) []*image.Paletted {
+	palette := gifPalette(images)
+	indices := make(map[color.NRGBA]uint8)
+	normalized := make([]*image.Paletted, 0, len(images))
+	for _, img := range images {
+		// Index 0 is transparent, so transparent pixels are left untouched.
+		palettedImage := image.NewPaletted(img.Rect, palette)
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				c := img.NRGBAAt(x, y)
+				if c.A < minGifAlpha {
+					continue
+				}
+				c.A = 0xff
+				index, ok := indices[c]
+				if !ok {
+					index = uint8(palette[1:].Index(c) + 1)
+					indices[c] = index
+				}
+				palettedImage.SetColorIndex(x, y, index)
+			}
+		}
 		normalized = append(normalized, palettedImage)
 	}
-	return normalized, nil
+	return normalized
+}
+
+// gifPalette returns a palette for all images whose first color is transparent. It holds the exact colors of the
+// opaque pixels if there are at most 255 of them, otherwise they are quantized to 255 colors.
+func gifPalette(images []*image.NRGBA) color.Palette {
+	colors := make(map[color.NRGBA]bool)
+	pixels := 0
+	for _, img := range images {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				if c := img.NRGBAAt(x, y); c.A >= minGifAlpha {
+					c.A = 0xff
+					colors[c] = true
+					pixels++
+				}
+			}
+		}
+	}
+
+	palette := color.Palette{color.Transparent}
+	if len(colors) <= 255 {
+		exact := make([]color.NRGBA, 0, len(colors))
+		for c := range colors {
+			exact = append(exact, c)
+		}
+		sort.Slice(exact, func(i, j int) bool {
+			a, b := exact[i], exact[j]
+			return a.R < b.R || a.R == b.R && (a.G < b.G || a.G == b.G && a.B < b.B)
+		})
+		for _, c := range exact {
+			palette = append(palette, c)
+		}
+		return palette
+	}
+
+	// Quantize the opaque pixels of all images at once, lined up in a single row, so the images share the palette.
+	opaque := image.NewNRGBA(image.Rect(0, 0, pixels, 1))
+	i := 0
+	for _, img := range images {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				if c := img.NRGBAAt(x, y); c.A >= minGifAlpha {
+					c.A = 0xff
+					opaque.SetNRGBA(i, 0, c)
+					i++
+				}
+			}
+		}
+	}
+	quantizer := gogif.MedianCutQuantizer{NumColor: 255}
+	quantized := image.NewPaletted(opaque.Rect, nil)
+	quantizer.Quantize(quantized, opaque.Rect, opaque, image.Point{})
+	return append(palette, quantized.Palette...)
 }
 
 func createGif(imgs []*image.Paletted) *gif.GIF {
@@ -144,14 +238,59 @@ func createGif(imgs []*image.Paletted) *gif.GIF {
 	return outGif
 }
 
-func writeGif(path string, imgs []*image.Paletted) error {
-	outGif := createGif(imgs)
-	file, err := os.Create(path)
+func writeGif(path string, frames []*image.NRGBA) error {
+	file, err := createFile(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	return gif.EncodeAll(file, outGif)
+	return gif.EncodeAll(file, createGif(normalizeImages(frames)))
+}
+
+// writeAPNG writes the frames as animated PNG, which keeps their colors and transparency.
+func writeAPNG(path string, frames []*image.NRGBA) error {
+	anim := &apng.APNG{}
+	for _, frame := range frames {
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 20)
+	}
+	file, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return apng.Encode(file, anim)
+}
+
+// writeStrip writes the frames next to each other into a single image, advancing in the provided direction.
+func writeStrip(path string, frames []*image.NRGBA, direction image.Point) error {
+	size := frames[0].Rect.Size()
+	step := image.Pt(size.X*direction.X, size.Y*direction.Y)
+	strip := image.NewNRGBA(image.Rectangle{Max: size.Add(step.Mul(len(frames) - 1))})
+	for i, frame := range frames {
+		draw.Draw(strip, frame.Rect.Add(step.Mul(i)), frame, image.Point{}, draw.Src)
+	}
+	return writeImage(path, strip)
+}
+
+// writePNGSequence writes the frames as numbered images into the directory at the provided path.
+func writePNGSequence(path string, frames []*image.NRGBA) error {
+	digits := len(strconv.Itoa(len(frames) - 1))
+	for i, frame := range frames {
+		if err := writeImage(filepath.Join(path, fmt.Sprintf("%0*d.png", digits, i)), frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createFile creates the file at the provided path along with its parent directories, which are part of the names of
+// some frames.
+func createFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
 }
 
 func main() {
@@ -161,18 +300,28 @@ func main() {
 	}
 	out := flag.String("o", wd, "Specifies the output path.")
 	size := flag.Int("size", 100, "Specifies size of the images.")
-	gifFlag := flag.Bool("gif", false, "Creates gifs from connected frames.")
+	format := flag.String("format", "", "Writes connected frames as animations instead of single images, "+
+		"as gif, apng, strip-h, strip-v or png-seq.")
+	gifFlag := flag.Bool("gif", false, "Creates gifs from connected frames, same as -format gif.")
 	flag.Parse()
 
+	if *gifFlag && *format == "" {
+		*format = "gif"
+	}
+	animFormat, ok := animationFormats[*format]
+	if !ok && *format != "" {
+		fmt.Fprintf(os.Stderr, "unknown format %s\n", *format)
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	path := flag.Arg(0)
 	sheet, err := texturepacker.Open(path)
 	if err != nil {
 		panic(err)
 	}
 
-	// The game's packed textures only contain a single entry right now.
-	texture := sheet.Textures[0]
-	images, err := cropFrames(path, *size, texture)
+	images, err := cropFrames(sheet, *size)
 	if err != nil {
 		panic(err)
 	}
@@ -186,18 +335,11 @@ func main() {
 		panic(err)
 	}
 
-	if *gifFlag {
-		gifs, err := sortImages(images)
-		if err != nil {
-			panic(err)
-		}
-		// Normalizing and writing each gif
-		for name, imageSeries := range gifs {
-			normalized, err := normalizeImages(imageSeries)
-			if err != nil {
-				panic(err)
-			}
-			if err := writeGif(filepath.Join(*out, name+".gif"), normalized); err != nil {
+	if *format != "" {
+		animations := animationImages(sheet, images)
+		// Normalizing and writing each animation
+		for name, imageSeries := range animations {
+			if err := animFormat.write(filepath.Join(*out, name+animFormat.ext), fitImages(imageSeries)); err != nil {
 				panic(err)
 			}
 		}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFrames returns frames of 2x3 pixels, each filled with a different color.
+func testFrames(n int) []*image.NRGBA {
+	var frames []*image.NRGBA
+	for i := 0; i < n; i++ {
+		frame := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 2; x++ {
+				frame.SetNRGBA(x, y, color.NRGBA{uint8(i * 40), uint8(x * 100), uint8(y * 100), 0xff})
+			}
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func readImage(t *testing.T, path string) image.Image {
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	img, err := png.Decode(file)
+	assert.NoError(t, err)
+	return img
+}
+
+func Test_writeStrip(t *testing.T) {
+	frames := testFrames(3)
+	for _, test := range []struct {
+		direction image.Point
+		size      image.Point
+	}{
+		{image.Pt(1, 0), image.Pt(6, 3)},
+		{image.Pt(0, 1), image.Pt(2, 9)},
+	} {
+		path := filepath.Join(t.TempDir(), "strip.png")
+		assert.NoError(t, writeStrip(path, frames, test.direction))
+
+		strip := readImage(t, path)
+		assert.Equal(t, test.size, strip.Bounds().Size())
+		for i, frame := range frames {
+			offset := image.Pt(2*i*test.direction.X, 3*i*test.direction.Y)
+			for y := 0; y < 3; y++ {
+				for x := 0; x < 2; x++ {
+					assert.Equal(t, frame.NRGBAAt(x, y), color.NRGBAModel.Convert(strip.At(x+offset.X, y+offset.Y)))
+				}
+			}
+		}
+	}
+}
+
+func Test_writePNGSequence(t *testing.T) {
+	frames := testFrames(11)
+	path := filepath.Join(t.TempDir(), "Bat.frames")
+	assert.NoError(t, writePNGSequence(path, frames))
+
+	files, err := os.ReadDir(path)
+	assert.NoError(t, err)
+	assert.Len(t, files, 11)
+	assert.Equal(t, "00.png", files[0].Name())
+	assert.Equal(t, "10.png", files[10].Name())
+	img := readImage(t, filepath.Join(path, "10.png"))
+	assert.Equal(t, frames[10].NRGBAAt(1, 2), color.NRGBAModel.Convert(img.At(1, 2)))
+}
+
+func Test_animationFormats(t *testing.T) {
+	dir := t.TempDir()
+	exts := map[string]string{".png": "single images"}
+	for name, format := range animationFormats {
+		other, exists := exts[format.ext]
+		assert.False(t, exists, "%s writes the same files as %s", name, other)
+		exts[format.ext] = name
+
+		path := filepath.Join(dir, "Bat"+format.ext)
+		assert.NoError(t, format.write(path, testFrames(2)), name)
+		_, err := os.Stat(path)
+		assert.NoError(t, err, name)
+	}
+}
+
+func Test_normalizeImagesExactPalette(t *testing.T) {
+	// 5 frames of 8x8 pixels hold 255 distinct colors and a transparent pixel.
+	var frames []*image.NRGBA
+	for i := 0; i < 5; i++ {
+		frame := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+		for p := 0; p < 64; p++ {
+			if n := i*64 + p; n < 255 {
+				frame.SetNRGBA(p%8, p/8, color.NRGBA{uint8(n), uint8(n / 2), 7, 0xff})
+			}
+		}
+		frames = append(frames, frame)
+	}
+	// Semi-transparent pixels become opaque.
+	frames[0].SetNRGBA(0, 0, color.NRGBA{0, 0, 7, minGifAlpha})
+
+	normalized := normalizeImages(frames)
+	assert.Len(t, normalized[0].Palette, 256)
+	assert.Equal(t, color.Transparent, normalized[0].Palette[0])
+	for i, frame := range frames {
+		assert.Equal(t, normalized[0].Palette, normalized[i].Palette)
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				expected := frame.NRGBAAt(x, y)
+				if expected.A != 0 {
+					expected.A = 0xff
+				}
+				assert.Equal(t, expected, color.NRGBAModel.Convert(normalized[i].At(x, y)))
+			}
+		}
+	}
+}
+
+func Test_normalizeImagesQuantized(t *testing.T) {
+	frame := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			frame.SetNRGBA(x, y, color.NRGBA{uint8(x * 8), uint8(y * 8), 100, 0xff})
+		}
+	}
+	frame.SetNRGBA(0, 0, color.NRGBA{})
+
+	normalized := normalizeImages([]*image.NRGBA{frame})
+	assert.LessOrEqual(t, len(normalized[0].Palette), 256)
+	assert.Equal(t, color.Transparent, normalized[0].Palette[0])
+	assert.Equal(t, uint8(0), normalized[0].ColorIndexAt(0, 0))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if x > 0 || y > 0 {
+				assert.NotEqual(t, uint8(0), normalized[0].ColorIndexAt(x, y))
+			}
+		}
+	}
+}
+
+func Test_normalizeImagesTransparentFrame(t *testing.T) {
+	frames := testFrames(2)
+	frames = append(frames, image.NewNRGBA(frames[0].Rect))
+
+	normalized := normalizeImages(frames)
+	assert.Equal(t, color.Transparent, normalized[2].Palette[0])
+	for _, index := range normalized[2].Pix {
+		assert.Equal(t, uint8(0), index)
+	}
+	assert.Len(t, normalized[0].Palette, 1+12)
+
+	// Frames which are all transparent result in a palette holding just the transparent color.
+	normalized = normalizeImages([]*image.NRGBA{image.NewNRGBA(image.Rect(0, 0, 2, 2))})
+	assert.Equal(t, color.Palette{color.Transparent}, normalized[0].Palette)
+	assert.Equal(t, []uint8{0, 0, 0, 0}, normalized[0].Pix)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hochbaum/vampire-survivors-tools/script"
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+func runApply(path string, args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	dryRun := flags.Bool("n", false, "Only prints the changes without storing them.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := expectArgs(flags.Args(), 1); err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	program, err := script.Parse(string(src))
+	if err != nil {
+		return err
+	}
+
+	save, db, err := vampires.OpenSaveFile(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	original := save.Clone()
+	if err := program.Run(script.NewSaveEnv(save)); err != nil {
+		return err
+	}
+	if err := save.Validate(); err != nil {
+		return err
+	}
+
+	changes := vampires.Diff(original, save)
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	backup, err := vampires.BackupSaveFile(path)
+	if err != nil {
+		return fmt.Errorf("could not back up save file: %w", err)
+	}
+	if _, err := vampires.StoreSaveFile(save, db); err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d changes, backup at %s.\n", len(changes), backup)
+	return nil
+}
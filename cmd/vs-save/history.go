@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hochbaum/vampire-survivors-tools/history"
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+func runRecord(path string, args []string) error {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	out := flags.String("o", "history.jsonl", "Specifies the history file to append to.")
+	interval := flags.Duration("interval", time.Minute, "Specifies the interval in which the save file is read.")
+	onlyChanges := flags.Bool("changes", false, "Only records snapshots if the save file changed.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	recorder, err := history.OpenRecorder(*out)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+	recorder.OnlyChanges = *onlyChanges
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = recorder.Watch(ctx, *interval, func() (*vampires.SaveFile, error) {
+		return vampires.OpenSaveFileReadOnly(path)
+	}, func(err error) {
+		fmt.Fprintf(os.Stderr, "could not record save file: %v\n", err)
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func runHistory(_ string, args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	in := flags.String("i", "history.jsonl", "Specifies the history file to query.")
+	gap := flags.Duration("gap", 30*time.Minute, "Specifies the time without snapshots which ends a session.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one of the queries kills or coins")
+	}
+
+	h, err := history.Open(*in)
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "kills":
+		kills, err := h.KillsPerDay(time.Local)
+		if err != nil {
+			return err
+		}
+		return kills.WriteCSV(os.Stdout)
+	case "coins":
+		sessions, err := h.CoinsPerSession(*gap)
+		if err != nil {
+			return err
+		}
+		return sessions.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown query %s, expected kills or coins", flags.Arg(0))
+	}
+}
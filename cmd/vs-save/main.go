@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// command defines a sub command of vs-save.
+type command struct {
+	usage       string
+	description string
+	run         func(path string, args []string) error
+}
+
+// commands maps the names of all sub commands to their implementation.
+var commands = map[string]command{
+	"keys":   {"keys", "Lists all keys of the save file.", runKeys},
+	"get":    {"get <key>", "Prints the JSON value stored at a key.", runGet},
+	"put":    {"put <key> <json>", "Stores a JSON value at a key.", runPut},
+	"delete": {"delete <key>", "Deletes a key.", runDelete},
+	"schema": {"schema", "Prints the schema version of the save file.", runSchema},
+
+	"recover": {"recover [-o <path>]", "Recovers a damaged save file.", runRecover},
+	"record":  {"record [-o <file>]", "Records the progression of the save file.", runRecord},
+	"history": {"history [-i <file>] kills|coins", "Prints recorded progression as CSV.", runHistory},
+	"tui":     {"tui [-catalog <file>]", "Edits the save file interactively.", runTUI},
+	"apply":   {"apply [-n] <script>", "Transforms the save file using a script.", runApply},
+}
+
+// defaultPath returns the location of the save file of the Electron builds of the game.
+func defaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "Vampire Survivors", "Local Storage", "leveldb")
+}
+
+func usage() {
+	fmt.Fprintln(flag.CommandLine.Output(), "Usage: vs-save [-path <save file>] <command> [arguments]")
+	fmt.Fprintln(flag.CommandLine.Output(), "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %-32s %s\n", commands[name].usage, commands[name].description)
+	}
+	fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	path := flag.String("path", defaultPath(), "Specifies the path to the LevelDB of the save file.")
+	flag.Usage = usage
+	flag.Parse()
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(*path, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "vs-save %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
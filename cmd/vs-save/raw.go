@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// withStorage opens the LevelDB at the provided path, passes it to fn and closes it afterwards.
+func withStorage(path string, fn func(db vampires.SaveStorage) error) error {
+	db, err := leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return fn(db)
+}
+
+// expectArgs returns an error if the number of arguments does not match.
+func expectArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d arguments but got %d", n, len(args))
+	}
+	return nil
+}
+
+func runKeys(path string, args []string) error {
+	if err := expectArgs(args, 0); err != nil {
+		return err
+	}
+	return withStorage(path, func(db vampires.SaveStorage) error {
+		keys, err := vampires.ListKeys(db)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+		return nil
+	})
+}
+
+func runGet(path string, args []string) error {
+	if err := expectArgs(args, 1); err != nil {
+		return err
+	}
+	return withStorage(path, func(db vampires.SaveStorage) error {
+		value, err := vampires.GetRaw(db, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(value))
+		return nil
+	})
+}
+
+func runPut(path string, args []string) error {
+	if err := expectArgs(args, 2); err != nil {
+		return err
+	}
+	return withStorage(path, func(db vampires.SaveStorage) error {
+		return vampires.PutRaw(db, args[0], []byte(args[1]))
+	})
+}
+
+func runDelete(path string, args []string) error {
+	if err := expectArgs(args, 1); err != nil {
+		return err
+	}
+	return withStorage(path, func(db vampires.SaveStorage) error {
+		return vampires.DeleteKey(db, args[0])
+	})
+}
+
+func runSchema(path string, args []string) error {
+	if err := expectArgs(args, 0); err != nil {
+		return err
+	}
+	return withStorage(path, func(db vampires.SaveStorage) error {
+		schema, err := vampires.DetectSchema(db)
+		if err != nil {
+			return err
+		}
+		fmt.Println(schema.Version)
+		return nil
+	})
+}
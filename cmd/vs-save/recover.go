@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func runRecover(path string, args []string) error {
+	flags := flag.NewFlagSet("recover", flag.ExitOnError)
+	out := flags.String("o", "", "Writes the recovered save file into a new LevelDB at the provided path.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	_, report, err := vampires.RecoverSave(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Save file is %s.\n", report.Method)
+	for _, key := range report.Salvaged {
+		fmt.Printf("salvaged: %s\n", key)
+	}
+	for _, key := range report.Lost {
+		fmt.Printf("lost:     %s\n", key)
+	}
+
+	if *out == "" {
+		return nil
+	}
+	db, err := leveldb.OpenFile(*out, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// The entries are copied as they were read, so the read-only fields and unknown keys are recovered as well.
+	return report.WriteEntries(db)
+}
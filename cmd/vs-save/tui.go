@@ -0,0 +1,606 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+	"golang.org/x/term"
+)
+
+// categories defines the order in which the categories of the fields are listed.
+var categories = []string{"Progress", "Unlocks", "Counters", "Settings"}
+
+// key defines a key pressed by the user, either a printable rune or one of the constants below.
+type key rune
+
+const (
+	keyUp key = -1 - iota
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyInterrupt
+	keyUnknown
+)
+
+// sequences maps the escape sequences sent by terminals to keys.
+var sequences = map[string]key{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+}
+
+// parseKeys parses the input read from a terminal in raw mode into keys. A single read may contain several keys, e.g.
+// when text is pasted.
+func parseKeys(input []byte) []key {
+	var keys []key
+	for len(input) > 0 {
+		if input[0] == '\x1b' {
+			k, n := keyEscape, 1
+			for sequence, sk := range sequences {
+				if bytes.HasPrefix(input, []byte(sequence)) {
+					k, n = sk, len(sequence)
+				}
+			}
+			if k == keyEscape && len(input) > 1 && (input[1] == '[' || input[1] == 'O') {
+				// Skip unsupported sequences up to their final byte.
+				k, n = keyUnknown, 2
+				for n < len(input) && (input[n] < 0x40 || input[n] > 0x7e) {
+					n++
+				}
+				if n < len(input) {
+					n++
+				}
+			}
+			keys = append(keys, k)
+			input = input[n:]
+			continue
+		}
+
+		r, n := utf8.DecodeRune(input)
+		input = input[n:]
+		switch {
+		case r == '\r' || r == '\n':
+			keys = append(keys, keyEnter)
+		case r == 0x7f || r == 0x08:
+			keys = append(keys, keyBackspace)
+		case r == 0x03:
+			keys = append(keys, keyInterrupt)
+		case r >= ' ' && r != utf8.RuneError:
+			keys = append(keys, key(r))
+		default:
+			keys = append(keys, keyUnknown)
+		}
+	}
+	return keys
+}
+
+// screen defines what the editor currently shows.
+type screen int
+
+const (
+	screenFields screen = iota
+	screenChecklist
+	screenCounters
+	screenDiff
+)
+
+// prompt is an input line shown at the bottom of the editor.
+type prompt struct {
+	label  string
+	text   string
+	submit func(text string) error
+}
+
+// editor is the state of the interactive save file editor.
+type editor struct {
+	path     string
+	db       vampires.SaveStorage
+	save     *vampires.SaveFile
+	original *vampires.SaveFile
+	fields   []vampires.Field
+
+	screen screen
+	// cursor holds the selected line of each screen.
+	cursor map[screen]int
+	// field holds the field edited on the checklist and counters screens.
+	field vampires.Field
+	// items holds the IDs listed on the checklist screen or the keys listed on the counters screen.
+	items  []string
+	prompt *prompt
+	status string
+	// confirmQuit is set once the user has been warned about quitting with unsaved changes.
+	confirmQuit bool
+	quit        bool
+}
+
+func runTUI(path string, args []string) error {
+	flags := flag.NewFlagSet("tui", flag.ExitOnError)
+	catalog := flags.String("catalog", "", "Specifies a JSON file mapping field names to additional IDs to offer.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *catalog != "" {
+		if err := vampires.LoadCatalog(*catalog); err != nil {
+			return err
+		}
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("standard input and output must be a terminal")
+	}
+
+	save, db, err := vampires.OpenSaveFile(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ed := &editor{path: path, db: db, save: save, original: save.Clone(), cursor: make(map[screen]int)}
+	for _, category := range categories {
+		for _, field := range save.Fields() {
+			if field.Category == category {
+				ed.fields = append(ed.fields, field)
+			}
+		}
+	}
+
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	// Switch to the alternate screen and hide the cursor, restoring both on exit.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	out := bufio.NewWriter(os.Stdout)
+	buf := make([]byte, 256)
+	for !ed.quit {
+		ed.render(out)
+		if err := out.Flush(); err != nil {
+			return err
+		}
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			ed.handle(k)
+		}
+	}
+	return nil
+}
+
+// lines returns the title and the lines of the current screen, along with the index of the selected line.
+func (e *editor) lines() (string, []string, int) {
+	switch e.screen {
+	case screenChecklist:
+		selected := make(map[string]bool)
+		for _, id := range e.field.Value.Interface().([]string) {
+			selected[id] = true
+		}
+		lines := make([]string, len(e.items))
+		for i, id := range e.items {
+			lines[i] = fmt.Sprintf("  %s %s", checkbox(selected[id]), id)
+		}
+		return e.field.Name, lines, e.cursor[e.screen]
+
+	case screenCounters:
+		counters := e.field.Value.Interface().(map[string]int32)
+		lines := make([]string, len(e.items))
+		for i, k := range e.items {
+			lines[i] = fmt.Sprintf("  %-32s %d", k, counters[k])
+		}
+		return e.field.Name, lines, e.cursor[e.screen]
+
+	case screenDiff:
+		var lines []string
+		for _, change := range vampires.Diff(e.original, e.save) {
+			lines = append(lines, "  "+change.String())
+		}
+		if len(lines) == 0 {
+			lines = []string{"  No changes."}
+		}
+		return "Changes", lines, -1
+
+	default:
+		changed := make(map[string]bool)
+		for _, change := range vampires.Diff(e.original, e.save) {
+			changed[change.Field] = true
+		}
+		var lines []string
+		selected := -1
+		category := ""
+		for i, field := range e.fields {
+			if field.Category != category {
+				category = field.Category
+				lines = append(lines, fmt.Sprintf("\x1b[1m%s\x1b[0m", category))
+			}
+			if i == e.cursor[e.screen] {
+				selected = len(lines)
+			}
+			marker := " "
+			if changed[field.Name] {
+				marker = "*"
+			}
+			lines = append(lines, fmt.Sprintf(" %s%-24s %s", marker, field.Name, describe(field.Value)))
+		}
+		return "Fields", lines, selected
+	}
+}
+
+// help returns the keys available on the current screen.
+func (e *editor) help() string {
+	switch e.screen {
+	case screenChecklist:
+		return "space: toggle  a: add ID  esc: back"
+	case screenCounters:
+		return "enter: edit  a: add  d: delete  esc: back"
+	case screenDiff:
+		return "y: back up and save  n: back"
+	default:
+		return "enter: edit  s: review and save  q: quit"
+	}
+}
+
+// render draws the current screen, scrolling it so that the selected line is visible.
+func (e *editor) render(out *bufio.Writer) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	title, lines, selected := e.lines()
+
+	// The title, the status and the help or prompt line take three lines.
+	rows := height - 3
+	if rows < 1 {
+		rows = 1
+	}
+	offset := 0
+	if selected >= rows {
+		offset = selected - rows + 1
+	}
+
+	fmt.Fprint(out, "\x1b[H\x1b[2J")
+	fmt.Fprintf(out, "\x1b[7m%s\x1b[0m\r\n", truncate(fmt.Sprintf(" vs-save: %s - %s", e.path, title), width))
+	for i := offset; i < len(lines) && i < offset+rows; i++ {
+		line := truncate(lines[i], width)
+		if i == selected {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		fmt.Fprintf(out, "%s\r\n", line)
+	}
+
+	fmt.Fprintf(out, "\x1b[%d;1H%s\r\n", height-1, truncate(e.status, width))
+	if e.prompt != nil {
+		fmt.Fprint(out, truncate(e.prompt.label+e.prompt.text+"_", width))
+	} else {
+		fmt.Fprintf(out, "\x1b[2m%s\x1b[0m", truncate(e.help(), width))
+	}
+}
+
+// handle updates the editor according to the pressed key.
+func (e *editor) handle(k key) {
+	if k == keyInterrupt {
+		e.quit = true
+		return
+	}
+	if e.prompt != nil {
+		e.handlePrompt(k)
+		return
+	}
+	e.status = ""
+
+	_, lines, _ := e.lines()
+	count := len(lines)
+	if e.screen == screenFields {
+		count = len(e.fields)
+	}
+	cursor := e.cursor[e.screen]
+	switch k {
+	case keyUp, 'k':
+		cursor--
+	case keyDown, 'j':
+		cursor++
+	case keyPageUp:
+		cursor -= 10
+	case keyPageDown:
+		cursor += 10
+	}
+	if cursor >= count {
+		cursor = count - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	e.cursor[e.screen] = cursor
+
+	switch e.screen {
+	case screenFields:
+		e.handleFields(k, cursor)
+	case screenChecklist:
+		e.handleChecklist(k, cursor)
+	case screenCounters:
+		e.handleCounters(k, cursor)
+	case screenDiff:
+		e.handleDiff(k)
+	}
+}
+
+func (e *editor) handlePrompt(k key) {
+	switch k {
+	case keyEscape:
+		e.prompt = nil
+	case keyBackspace:
+		if r := []rune(e.prompt.text); len(r) > 0 {
+			e.prompt.text = string(r[:len(r)-1])
+		}
+	case keyEnter:
+		p := e.prompt
+		e.prompt = nil
+		if err := p.submit(p.text); err != nil {
+			e.status = err.Error()
+		}
+	default:
+		if k >= ' ' {
+			e.prompt.text += string(rune(k))
+		}
+	}
+}
+
+func (e *editor) handleFields(k key, cursor int) {
+	switch k {
+	case 's':
+		e.screen = screenDiff
+	case 'q', keyEscape:
+		if len(vampires.Diff(e.original, e.save)) > 0 && !e.confirmQuit {
+			e.confirmQuit = true
+			e.status = "There are unsaved changes, press q again to discard them."
+			return
+		}
+		e.quit = true
+		return
+	}
+	e.confirmQuit = false
+
+	switch k {
+	case keyEnter, ' ':
+		field := e.fields[cursor]
+		if field.ReadOnly {
+			e.status = field.Name + " is read-only."
+			return
+		}
+		switch field.Value.Kind() {
+		case reflect.Bool:
+			field.Value.SetBool(!field.Value.Bool())
+		case reflect.Slice:
+			e.field = field
+			e.items = appendMissing(vampires.Catalog[field.Name], field.Value.Interface().([]string)...)
+			e.screen = screenChecklist
+			e.cursor[e.screen] = 0
+		case reflect.Map:
+			e.field = field
+			e.items = nil
+			for k := range field.Value.Interface().(map[string]int32) {
+				e.items = append(e.items, k)
+			}
+			sort.Strings(e.items)
+			e.screen = screenCounters
+			e.cursor[e.screen] = 0
+		default:
+			e.prompt = &prompt{
+				label: field.Name + ": ",
+				text:  fmt.Sprint(field.Value.Interface()),
+				submit: func(text string) error {
+					return setValue(field.Value, text)
+				},
+			}
+		}
+	}
+}
+
+func (e *editor) handleChecklist(k key, cursor int) {
+	switch k {
+	case keyEscape:
+		e.screen = screenFields
+	case keyEnter, ' ':
+		if len(e.items) > 0 {
+			e.toggle(e.items[cursor])
+		}
+	case 'a':
+		e.prompt = &prompt{label: "Add ID: ", submit: func(text string) error {
+			id := strings.TrimSpace(text)
+			if id == "" {
+				return nil
+			}
+			e.items = appendMissing(e.items, id)
+			e.toggle(id)
+			return nil
+		}}
+	}
+}
+
+// toggle adds the ID to or removes it from the slice edited on the checklist screen.
+func (e *editor) toggle(id string) {
+	ids := e.field.Value.Interface().([]string)
+	toggled := make([]string, 0, len(ids)+1)
+	found := false
+	for _, existing := range ids {
+		if existing == id {
+			found = true
+			continue
+		}
+		toggled = append(toggled, existing)
+	}
+	if !found {
+		toggled = append(toggled, id)
+	}
+	e.field.Value.Set(reflect.ValueOf(toggled))
+}
+
+func (e *editor) handleCounters(k key, cursor int) {
+	counters := e.field.Value.Interface().(map[string]int32)
+	setCounter := func(name string) func(string) error {
+		return func(text string) error {
+			value, err := strconv.ParseInt(strings.TrimSpace(text), 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+			if counters == nil {
+				counters = make(map[string]int32)
+				e.field.Value.Set(reflect.ValueOf(counters))
+			}
+			counters[name] = int32(value)
+			e.items = appendMissing(e.items, name)
+			return nil
+		}
+	}
+
+	switch k {
+	case keyEscape:
+		e.screen = screenFields
+	case keyEnter:
+		if len(e.items) > 0 {
+			name := e.items[cursor]
+			e.prompt = &prompt{label: name + ": ", text: fmt.Sprint(counters[name]), submit: setCounter(name)}
+		}
+	case 'a':
+		e.prompt = &prompt{label: "Add key: ", submit: func(text string) error {
+			name := strings.TrimSpace(text)
+			if name == "" {
+				return nil
+			}
+			e.prompt = &prompt{label: name + ": ", text: "0", submit: setCounter(name)}
+			return nil
+		}}
+	case 'd':
+		if len(e.items) > 0 {
+			delete(counters, e.items[cursor])
+			e.items = append(e.items[:cursor:cursor], e.items[cursor+1:]...)
+		}
+	}
+}
+
+func (e *editor) handleDiff(k key) {
+	switch k {
+	case 'n', keyEscape:
+		e.screen = screenFields
+	case 'y':
+		if len(vampires.Diff(e.original, e.save)) == 0 {
+			e.screen = screenFields
+			return
+		}
+		if err := e.save.Validate(); err != nil {
+			e.status = err.Error()
+			return
+		}
+		backup, err := vampires.BackupSaveFile(e.path)
+		if err != nil {
+			e.status = fmt.Sprintf("could not back up save file: %v", err)
+			return
+		}
+		written, err := vampires.StoreSaveFile(e.save, e.db)
+		if err != nil {
+			e.status = fmt.Sprintf("could not store save file: %v", err)
+			return
+		}
+		e.original = e.save.Clone()
+		e.screen = screenFields
+		e.status = fmt.Sprintf("Wrote %d keys, backup at %s.", len(written), backup)
+	}
+}
+
+// setValue parses the text into the bool, string or numeric value.
+func setValue(v reflect.Value, text string) error {
+	text = strings.TrimSpace(text)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	default:
+		return fmt.Errorf("can't edit values of kind %s", v.Kind())
+	}
+	return nil
+}
+
+// describe returns a short description of the value shown in the list of fields.
+func describe(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return checkbox(v.Bool())
+	case reflect.Slice:
+		return fmt.Sprintf("%d entries", v.Len())
+	case reflect.Map:
+		return fmt.Sprintf("%d counters", v.Len())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// appendMissing returns a sorted copy of the IDs extended by the ones it does not contain yet.
+func appendMissing(ids []string, add ...string) []string {
+	result := append([]string{}, ids...)
+	present := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		present[id] = true
+	}
+	for _, id := range add {
+		if !present[id] {
+			present[id] = true
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// truncate shortens the line to the provided width of the terminal.
+func truncate(line string, width int) string {
+	if r := []rune(line); len(r) > width && !strings.Contains(line, "\x1b") {
+		return string(r[:width])
+	}
+	return line
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultPath returns the location of the save file of the Electron builds of the game.
+func defaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "Vampire Survivors", "Local Storage", "leveldb")
+}
+
+func main() {
+	path := flag.String("path", defaultPath(), "Specifies the path to the LevelDB of the save file.")
+	addr := flag.String("addr", "127.0.0.1:8337", "Specifies the address to listen on.")
+	interval := flag.Duration("interval", 2*time.Second, "Specifies how often the save file is checked for changes.")
+	origin := flag.String("origin", "", "Specifies the origin allowed to access the API from a browser, e.g. "+
+		"http://localhost:3000. Browsers are denied access if it is not set.")
+	flag.Parse()
+
+	srv := newServer(*path, *origin)
+	go srv.watch(*interval)
+
+	fmt.Printf("Serving %s on http://%s\n", *path, *addr)
+	if err := http.ListenAndServe(*addr, srv.routes()); err != nil {
+		panic(err)
+	}
+}
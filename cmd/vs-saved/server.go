@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+// server serves a save file over a JSON API. Reads work on snapshots of the save file, so they don't lock it, while
+// writes need the game to be closed.
+type server struct {
+	path   string
+	origin string
+
+	mu      sync.Mutex
+	save    *vampires.SaveFile
+	modTime time.Time
+	subs    map[chan []byte]struct{}
+}
+
+func newServer(path, origin string) *server {
+	return &server{path: path, origin: origin, subs: make(map[chan []byte]struct{})}
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/save", s.handleSave)
+	mux.HandleFunc("/save/", s.handleField)
+	mux.HandleFunc("/events", s.handleEvents)
+	return s.cors(mux)
+}
+
+// cors allows browsers to access the API from the configured origin, e.g. from stream overlays. Without a configured
+// origin no CORS headers are sent, and requests from any other origin are rejected, so websites opened in the browser
+// can neither read nor change the save file.
+func (s *server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// Requests which are not sent by a browser, e.g. by curl.
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.origin == "" || origin != s.origin {
+			writeError(w, http.StatusForbidden, fmt.Errorf("origin %s is not allowed", origin))
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", s.origin)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, PATCH, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Vary", "Origin")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// latestModTime returns the latest modification time of the files of the LevelDB, which tells whether the save file has
+// to be read again.
+func (s *server) latestModTime() (time.Time, error) {
+	files, err := os.ReadDir(s.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var latest time.Time
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// load returns the current state of the save file, reading it again only if it has been modified. It reports whether
+// the save file changed since the last call.
+func (s *server) load() (*vampires.SaveFile, bool, error) {
+	modTime, err := s.latestModTime()
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.save != nil && modTime.Equal(s.modTime) {
+		return s.save, false, nil
+	}
+
+	save, err := vampires.OpenSaveFileReadOnly(s.path)
+	if err != nil {
+		return nil, false, err
+	}
+	changed := s.save != nil
+	s.save, s.modTime = save, modTime
+	return save, changed, nil
+}
+
+func (s *server) handleSave(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		save, _, err := s.load()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, save)
+	case http.MethodPatch:
+		s.handlePatch(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+func (s *server) handleField(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	save, _, err := s.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/save/")
+	field, ok := save.Field(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown field %s", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, field.Value.Interface())
+}
+
+// handlePatch applies a JSON object mapping field names or keys to their new values to the save file.
+func (s *server) handlePatch(w http.ResponseWriter, r *http.Request) {
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	save, db, err := vampires.OpenSaveFile(s.path)
+	if err != nil {
+		// The LevelDB is locked while the game is running.
+		writeError(w, http.StatusConflict, fmt.Errorf("could not open save file, is the game running? %w", err))
+		return
+	}
+	defer db.Close()
+
+	for name, value := range patch {
+		field, ok := save.Field(name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown field %s", name))
+			return
+		}
+		if field.ReadOnly {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("field %s is read-only", name))
+			return
+		}
+		// Unmarshal into a fresh value, as unmarshaling into the current one merges maps instead of replacing them.
+		target := reflect.New(field.Value.Type())
+		if err := json.Unmarshal(value, target.Interface()); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid value for %s: %w", name, err))
+			return
+		}
+		field.Value.Set(target.Elem())
+	}
+	if err := save.Validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	written, err := vampires.StoreSaveFile(save, db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if written == nil {
+		written = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"written": written})
+}
+
+// handleEvents streams the fields of the save file which changed as server-sent events.
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	events := make(chan []byte, 8)
+	s.mu.Lock()
+	s.subs[events] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, events)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", event)
+			flusher.Flush()
+		}
+	}
+}
+
+// watch checks the save file for changes in the provided interval and publishes the changed fields to all subscribers
+// of handleEvents.
+func (s *server) watch(interval time.Duration) {
+	var previous map[string]interface{}
+	for range time.Tick(interval) {
+		save, changed, err := s.load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not read save file: %v\n", err)
+			continue
+		}
+
+		current := make(map[string]interface{})
+		for _, field := range save.Fields() {
+			current[field.Name] = field.Value.Interface()
+		}
+		if previous == nil || !changed {
+			previous = current
+			continue
+		}
+
+		diff := make(map[string]interface{})
+		for name, value := range current {
+			if !jsonEqual(previous[name], value) {
+				diff[name] = value
+			}
+		}
+		previous = current
+		if len(diff) == 0 {
+			continue
+		}
+
+		event, err := json.Marshal(diff)
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		for sub := range s.subs {
+			select {
+			case sub <- event:
+			default:
+				// Slow subscribers miss events rather than blocking everyone else.
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// jsonEqual reports whether both values have the same JSON representation.
+func jsonEqual(a, b interface{}) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(da) == string(db)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func Test_cors(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, test := range []struct {
+		configured, origin, method string
+		status                     int
+		allowOrigin                string
+	}{
+		{"", "", http.MethodPatch, http.StatusOK, ""},
+		{"", "http://evil.example", http.MethodGet, http.StatusForbidden, ""},
+		{"", "http://evil.example", http.MethodPatch, http.StatusForbidden, ""},
+		{"http://localhost:3000", "http://evil.example", http.MethodPatch, http.StatusForbidden, ""},
+		{"http://localhost:3000", "http://localhost:3000", http.MethodPatch, http.StatusOK, "http://localhost:3000"},
+		{"http://localhost:3000", "http://localhost:3000", http.MethodOptions, http.StatusNoContent,
+			"http://localhost:3000"},
+	} {
+		req := httptest.NewRequest(test.method, "/save", nil)
+		if test.origin != "" {
+			req.Header.Set("Origin", test.origin)
+		}
+		rec := httptest.NewRecorder()
+		newServer("", test.configured).cors(next).ServeHTTP(rec, req)
+
+		assert.Equal(t, test.status, rec.Code, "%+v", test)
+		assert.Equal(t, test.allowOrigin, rec.Header().Get("Access-Control-Allow-Origin"), "%+v", test)
+	}
+}
+
+// createTestSave creates a LevelDB save file holding some coins and kills.
+func createTestSave(t *testing.T) string {
+	path := t.TempDir()
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, vampires.PutRaw(db, "CapacitorStorage.Coins", []byte("100")))
+	assert.NoError(t, vampires.PutRaw(db, "CapacitorStorage.LifetimeCoins", []byte("9000")))
+	assert.NoError(t, vampires.PutRaw(db, "CapacitorStorage.KillCount", []byte(`{"BAT1":3,"SKELETON":5}`)))
+	assert.NoError(t, db.Close())
+	return path
+}
+
+func serve(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func Test_handlePatch(t *testing.T) {
+	path := createTestSave(t)
+	handler := newServer(path, "").routes()
+
+	for _, test := range []struct {
+		body   string
+		status int
+		error  string
+	}{
+		{`{"LifetimeCoins":1}`, http.StatusBadRequest, "field LifetimeCoins is read-only"},
+		{`{"Unknown":1}`, http.StatusBadRequest, "unknown field Unknown"},
+		{`{"Coins":"many"}`, http.StatusBadRequest, "invalid value for Coins"},
+		{`{"Coins":-1}`, http.StatusUnprocessableEntity, "Coins must not be negative"},
+		{`{"MusicVolume":2}`, http.StatusUnprocessableEntity, "MusicVolume must not exceed 1"},
+	} {
+		rec := serve(handler, http.MethodPatch, "/save", test.body)
+		assert.Equal(t, test.status, rec.Code, test.body)
+		assert.Contains(t, rec.Body.String(), test.error, test.body)
+	}
+
+	// Maps are replaced as a whole, so enemies can be removed.
+	rec := serve(handler, http.MethodPatch, "/save", `{"Coins":250,"CapacitorStorage.KillCount":{"BAT1":7}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"written":["CapacitorStorage.Coins","CapacitorStorage.KillCount"]}`, rec.Body.String())
+
+	save, err := vampires.OpenSaveFileReadOnly(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 250.0, save.Coins)
+	assert.Equal(t, 9000.0, save.LifetimeCoins)
+	assert.Equal(t, map[string]int32{"BAT1": 7}, save.KillCount)
+}
+
+func Test_handleField(t *testing.T) {
+	handler := newServer(createTestSave(t), "").routes()
+
+	rec := serve(handler, http.MethodGet, "/save/Coins", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `100`, rec.Body.String())
+
+	rec = serve(handler, http.MethodGet, "/save/CapacitorStorage.KillCount", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"BAT1":3,"SKELETON":5}`, rec.Body.String())
+
+	rec = serve(handler, http.MethodGet, "/save/Unknown", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(handler, http.MethodPut, "/save/Coins", "1")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
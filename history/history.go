@@ -0,0 +1,200 @@
+// Package history records the progression of Vampire Survivors save files over time and answers questions about it,
+// like the number of kills per enemy and day.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+// record defines a line of a history file. The first record of a file contains every value of the save file, every
+// following record only contains the values which changed since the previous one.
+type record struct {
+	Time    time.Time                  `json:"t"`
+	Values  map[string]json.RawMessage `json:"v,omitempty"`
+	Removed []string                   `json:"r,omitempty"`
+}
+
+// Snapshot defines the state of a save file at a point in time.
+type Snapshot struct {
+	Time time.Time
+	// Values holds the JSON encoded values of the save file, mapped by their keys.
+	Values map[string]json.RawMessage
+}
+
+// Save decodes the values of the Snapshot into a SaveFile.
+func (s Snapshot) Save() (*vampires.SaveFile, error) {
+	db := vampires.NewMemoryStorage()
+	for key, value := range s.Values {
+		if err := vampires.PutRaw(db, key, value); err != nil {
+			return nil, err
+		}
+	}
+	save := new(vampires.SaveFile)
+	return save, vampires.UnmarshalSave(db, save)
+}
+
+// value decodes the value stored at the provided key of the Snapshot into v. Missing values leave v untouched.
+func (s Snapshot) value(key string, v interface{}) error {
+	data, ok := s.Values[key]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Recorder appends snapshots of a save file to a history file. Only the values which changed since the previous
+// snapshot are stored, which keeps the file compact.
+type Recorder struct {
+	// OnlyChanges makes the Recorder skip snapshots without any changes. Otherwise, these are recorded as well, which
+	// tells when the save file was observed, e.g. to detect sessions.
+	OnlyChanges bool
+
+	file *os.File
+	last map[string]json.RawMessage
+	now  func() time.Time
+}
+
+// OpenRecorder opens the history file located at the provided path for appending, creating it if necessary.
+func OpenRecorder(path string) (*Recorder, error) {
+	history, err := Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &Recorder{file: file, now: time.Now}
+	if history != nil && len(history.Snapshots) > 0 {
+		recorder.last = history.Snapshots[len(history.Snapshots)-1].Values
+	}
+	return recorder, nil
+}
+
+// Record appends a snapshot of the SaveFile to the history file, including the read-only lifetime statistics. It
+// reports whether a snapshot has been written, which is not the case if OnlyChanges is set and nothing changed.
+func (r *Recorder) Record(save *vampires.SaveFile) (bool, error) {
+	serialized, err := vampires.MarshalSaveAll(save)
+	if err != nil {
+		return false, err
+	}
+	values := serialized.Values()
+
+	rec := record{Time: r.now().UTC(), Values: make(map[string]json.RawMessage)}
+	for key, value := range values {
+		if last, ok := r.last[key]; !ok || !bytes.Equal(last, value) {
+			rec.Values[key] = value
+		}
+	}
+	for key := range r.last {
+		if _, ok := values[key]; !ok {
+			rec.Removed = append(rec.Removed, key)
+		}
+	}
+	sort.Strings(rec.Removed)
+
+	if r.OnlyChanges && r.last != nil && len(rec.Values) == 0 && len(rec.Removed) == 0 {
+		return false, nil
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return false, err
+	}
+	r.last = values
+	return true, nil
+}
+
+// Watch loads the save file using the provided function and records it in the provided interval, until the context is
+// cancelled. Errors returned by load are passed to onError, if set, and don't stop watching, as the save file might be
+// temporarily unavailable while the game writes to it.
+func (r *Recorder) Watch(ctx context.Context, interval time.Duration, load func() (*vampires.SaveFile, error),
+	onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		save, err := load()
+		if err == nil {
+			_, err = r.Record(save)
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes the history file.
+func (r *Recorder) Close() error {
+	return r.file.Close()
+}
+
+// History contains the snapshots of a history file in chronological order.
+type History struct {
+	Snapshots []Snapshot
+}
+
+// Open reads the history file located at the provided path.
+func Open(path string) (*History, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read reads a history file from the provided reader, replaying its records into full snapshots.
+func Read(r io.Reader) (*History, error) {
+	history := new(History)
+	values := make(map[string]json.RawMessage)
+
+	scanner := bufio.NewScanner(r)
+	// Records containing every value of a save file easily exceed the default limit of bufio.Scanner.
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, err
+		}
+
+		next := make(map[string]json.RawMessage, len(values))
+		for key, value := range values {
+			next[key] = value
+		}
+		for key, value := range rec.Values {
+			next[key] = value
+		}
+		for _, key := range rec.Removed {
+			delete(next, key)
+		}
+
+		values = next
+		history.Snapshots = append(history.Snapshots, Snapshot{rec.Time, values})
+	}
+	return history, scanner.Err()
+}
@@ -0,0 +1,88 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Recorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	recorder, err := OpenRecorder(path)
+	assert.NoError(t, err)
+	recorder.OnlyChanges = true
+
+	start := time.Date(2022, 1, 10, 18, 0, 0, 0, time.UTC)
+	now := start
+	recorder.now = func() time.Time { return now }
+
+	save := &vampires.SaveFile{Coins: 10, KillCount: map[string]int32{"BAT1": 5}}
+	written, err := recorder.Record(save)
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	now = now.Add(time.Minute)
+	written, err = recorder.Record(save)
+	assert.NoError(t, err)
+	assert.False(t, written, "unchanged snapshots must be skipped")
+
+	save.Coins = 30
+	written, err = recorder.Record(save)
+	assert.NoError(t, err)
+	assert.True(t, written)
+	assert.NoError(t, recorder.Close())
+
+	// Only the changed value must be part of the second record.
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"v":{"CapacitorStorage.Coins":30}`)
+
+	history, err := Open(path)
+	assert.NoError(t, err)
+	assert.Len(t, history.Snapshots, 2)
+	assert.Equal(t, start, history.Snapshots[0].Time)
+
+	restored, err := history.Snapshots[1].Save()
+	assert.NoError(t, err)
+	assert.Equal(t, 30.0, restored.Coins)
+	assert.Equal(t, map[string]int32{"BAT1": 5}, restored.KillCount)
+
+	// Reopening the history continues where it stopped.
+	recorder, err = OpenRecorder(path)
+	assert.NoError(t, err)
+	recorder.OnlyChanges = true
+	written, err = recorder.Record(save)
+	assert.NoError(t, err)
+	assert.False(t, written)
+	assert.NoError(t, recorder.Close())
+}
+
+func Test_RecorderCoinsPerSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	recorder, err := OpenRecorder(path)
+	assert.NoError(t, err)
+
+	now := time.Date(2022, 1, 10, 18, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+	for _, lifetimeCoins := range []float64{100, 250, 300} {
+		_, err := recorder.Record(&vampires.SaveFile{LifetimeCoins: lifetimeCoins})
+		assert.NoError(t, err)
+		now = now.Add(10 * time.Minute)
+	}
+	now = now.Add(24 * time.Hour)
+	_, err = recorder.Record(&vampires.SaveFile{LifetimeCoins: 1000})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Close())
+
+	history, err := Open(path)
+	assert.NoError(t, err)
+	sessions, err := history.CoinsPerSession(time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, 200.0, sessions[0].Coins)
+	assert.Equal(t, 700.0, sessions[1].Coins)
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+// dayLayout is the format of the days reported by KillsPerDay.
+const dayLayout = "2006-01-02"
+
+// DailyKill defines the number of kills of an enemy on a day.
+type DailyKill struct {
+	Day   string
+	Enemy string
+	Kills int32
+}
+
+// DailyKills is the result of History.KillsPerDay.
+type DailyKills []DailyKill
+
+// KillsPerDay returns the number of kills per enemy and day, based on the KillCount of the snapshots. Days are
+// determined in the provided location. Kills which happened while no snapshots were recorded are attributed to the next
+// day with a snapshot.
+func (h *History) KillsPerDay(loc *time.Location) (DailyKills, error) {
+	var result DailyKills
+	var baseline map[string]int32
+
+	for i := 0; i < len(h.Snapshots); {
+		day := h.Snapshots[i].Time.In(loc).Format(dayLayout)
+		if baseline == nil {
+			if err := h.Snapshots[i].value(string(vampires.KeyKillCount), &baseline); err != nil {
+				return nil, err
+			}
+		}
+
+		// Skip to the last snapshot of the day.
+		for i+1 < len(h.Snapshots) && h.Snapshots[i+1].Time.In(loc).Format(dayLayout) == day {
+			i++
+		}
+
+		var kills map[string]int32
+		if err := h.Snapshots[i].value(string(vampires.KeyKillCount), &kills); err != nil {
+			return nil, err
+		}
+		for enemy, count := range kills {
+			if delta := count - baseline[enemy]; delta > 0 {
+				result = append(result, DailyKill{day, enemy, delta})
+			}
+		}
+		baseline = kills
+		i++
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Day != result[j].Day {
+			return result[i].Day < result[j].Day
+		}
+		return result[i].Enemy < result[j].Enemy
+	})
+	return result, nil
+}
+
+// WriteCSV writes the DailyKills as CSV to the provided writer.
+func (d DailyKills) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"day", "enemy", "kills"})
+	for _, row := range d {
+		_ = writer.Write([]string{row.Day, row.Enemy, strconv.Itoa(int(row.Kills))})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Session defines a period of time in which the save file was observed without interruptions.
+type Session struct {
+	Start, End time.Time
+	// Coins holds the coins earned in the session, based on the LifetimeCoins of the save file, so spending coins
+	// doesn't affect it.
+	Coins float64
+}
+
+// Sessions is the result of History.CoinsPerSession.
+type Sessions []Session
+
+// CoinsPerSession splits the snapshots into sessions wherever no snapshot has been recorded for longer than the provided
+// gap and returns the coins earned in each session. Coins earned between two sessions are attributed to the latter.
+func (h *History) CoinsPerSession(gap time.Duration) (Sessions, error) {
+	var result Sessions
+	var baseline float64
+
+	for i := 0; i < len(h.Snapshots); {
+		session := Session{Start: h.Snapshots[i].Time}
+		if i == 0 {
+			if err := h.Snapshots[i].value(string(vampires.KeyLifetimeCoins), &baseline); err != nil {
+				return nil, err
+			}
+		}
+
+		for i+1 < len(h.Snapshots) && h.Snapshots[i+1].Time.Sub(h.Snapshots[i].Time) <= gap {
+			i++
+		}
+		session.End = h.Snapshots[i].Time
+
+		var coins float64
+		if err := h.Snapshots[i].value(string(vampires.KeyLifetimeCoins), &coins); err != nil {
+			return nil, err
+		}
+		session.Coins = coins - baseline
+		baseline = coins
+
+		result = append(result, session)
+		i++
+	}
+	return result, nil
+}
+
+// WriteCSV writes the Sessions as CSV to the provided writer.
+func (s Sessions) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"start", "end", "coins"})
+	for _, session := range s {
+		_ = writer.Write([]string{
+			session.Start.Format(time.RFC3339),
+			session.End.Format(time.RFC3339),
+			strconv.FormatFloat(session.Coins, 'f', -1, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestSnapshot(t time.Time, lifetimeCoins float64, kills string) Snapshot {
+	coins, _ := json.Marshal(lifetimeCoins)
+	return Snapshot{t, map[string]json.RawMessage{
+		"CapacitorStorage.LifetimeCoins": coins,
+		"CapacitorStorage.KillCount":     json.RawMessage(kills),
+	}}
+}
+
+func createTestHistory() *History {
+	day := time.Date(2022, 1, 10, 18, 0, 0, 0, time.UTC)
+	return &History{Snapshots: []Snapshot{
+		createTestSnapshot(day, 100, `{"BAT1":10}`),
+		createTestSnapshot(day.Add(10*time.Minute), 250, `{"BAT1":30,"GHOST":2}`),
+		createTestSnapshot(day.Add(20*time.Minute), 300, `{"BAT1":35,"GHOST":2}`),
+		createTestSnapshot(day.Add(24*time.Hour), 1000, `{"BAT1":50,"GHOST":4}`),
+	}}
+}
+
+func Test_KillsPerDay(t *testing.T) {
+	kills, err := createTestHistory().KillsPerDay(time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, DailyKills{
+		{"2022-01-10", "BAT1", 25},
+		{"2022-01-10", "GHOST", 2},
+		{"2022-01-11", "BAT1", 15},
+		{"2022-01-11", "GHOST", 2},
+	}, kills)
+
+	var buf bytes.Buffer
+	assert.NoError(t, kills[:1].WriteCSV(&buf))
+	assert.Equal(t, "day,enemy,kills\n2022-01-10,BAT1,25\n", buf.String())
+}
+
+func Test_CoinsPerSession(t *testing.T) {
+	history := createTestHistory()
+	sessions, err := history.CoinsPerSession(time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, 200.0, sessions[0].Coins)
+	assert.Equal(t, history.Snapshots[2].Time, sessions[0].End)
+	assert.Equal(t, 700.0, sessions[1].Coins)
+
+	var buf bytes.Buffer
+	assert.NoError(t, sessions[1:].WriteCSV(&buf))
+	assert.Equal(t, "start,end,coins\n2022-01-11T18:00:00Z,2022-01-11T18:00:00Z,700\n", buf.String())
+}
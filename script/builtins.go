@@ -0,0 +1,183 @@
+package script
+
+import (
+	"fmt"
+	"math"
+)
+
+// builtin is a function which can be called by scripts. Builtins must not modify their arguments.
+type builtin func(args []Value) (Value, error)
+
+// builtins maps the names of the functions available to scripts to their implementation.
+var builtins = map[string]builtin{
+	"len":      builtinLen,
+	"contains": builtinContains,
+	"add":      builtinAdd,
+	"remove":   builtinRemove,
+	"keys":     builtinKeys,
+	"min":      numbers(math.Min),
+	"max":      numbers(math.Max),
+	"floor":    number(math.Floor),
+	"abs":      number(math.Abs),
+}
+
+// builtinLen returns the length of a list, map or string.
+func builtinLen(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument but got %d", len(args))
+	}
+	switch v := args[0].(type) {
+	case []string:
+		return float64(len(v)), nil
+	case map[string]float64:
+		return float64(len(v)), nil
+	case string:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("can't get the length of a %s", typeName(args[0]))
+}
+
+// builtinContains reports whether a list contains a string or a map contains a key.
+func builtinContains(args []Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected 2 arguments but got %d", len(args))
+	}
+	s, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string but got a %s", typeName(args[1]))
+	}
+	switch v := args[0].(type) {
+	case []string:
+		for _, item := range v {
+			if item == s {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]float64:
+		_, ok := v[s]
+		return ok, nil
+	}
+	return nil, fmt.Errorf("expected a list or a map but got a %s", typeName(args[0]))
+}
+
+// builtinAdd returns a copy of a list extended by the strings it does not contain yet.
+func builtinAdd(args []Value) (Value, error) {
+	list, items, err := listArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	result := append([]string{}, list...)
+	contained := stringSet(list)
+	for _, item := range items {
+		if !contained[item] {
+			contained[item] = true
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// builtinRemove returns a copy of a list without the provided strings, or of a map without the provided keys.
+func builtinRemove(args []Value) (Value, error) {
+	if len(args) > 0 {
+		if m, ok := args[0].(map[string]float64); ok {
+			_, keys, err := listArgs(append([]Value{[]string{}}, args[1:]...))
+			if err != nil {
+				return nil, err
+			}
+			result := copyMap(m)
+			for _, key := range keys {
+				delete(result, key)
+			}
+			return result, nil
+		}
+	}
+
+	list, items, err := listArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(list))
+	removed := stringSet(items)
+	for _, item := range list {
+		if !removed[item] {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// stringSet returns a set of the strings, which keeps add and remove from taking quadratic time on long lists.
+func stringSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// builtinKeys returns the sorted keys of a map.
+func builtinKeys(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument but got %d", len(args))
+	}
+	m, ok := args[0].(map[string]float64)
+	if !ok {
+		return nil, fmt.Errorf("expected a map but got a %s", typeName(args[0]))
+	}
+	return sortedKeys(m), nil
+}
+
+// listArgs splits the arguments of add and remove into the list and the strings to add or remove. Lists are accepted in
+// place of strings.
+func listArgs(args []Value) ([]string, []string, error) {
+	if len(args) < 2 {
+		return nil, nil, fmt.Errorf("expected at least 2 arguments but got %d", len(args))
+	}
+	list, ok := args[0].([]string)
+	if !ok {
+		return nil, nil, fmt.Errorf("expected a list but got a %s", typeName(args[0]))
+	}
+	var items []string
+	for _, arg := range args[1:] {
+		switch v := arg.(type) {
+		case string:
+			items = append(items, v)
+		case []string:
+			items = append(items, v...)
+		default:
+			return nil, nil, fmt.Errorf("expected a string or a list but got a %s", typeName(arg))
+		}
+	}
+	return list, items, nil
+}
+
+// number wraps a function of one number into a builtin.
+func number(fn func(float64) float64) builtin {
+	return func(args []Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument but got %d", len(args))
+		}
+		x, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number but got a %s", typeName(args[0]))
+		}
+		return fn(x), nil
+	}
+}
+
+// numbers wraps a function of two numbers into a builtin.
+func numbers(fn func(float64, float64) float64) builtin {
+	return func(args []Value) (Value, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected 2 arguments but got %d", len(args))
+		}
+		x, ok := args[0].(float64)
+		y, ok2 := args[1].(float64)
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("expected numbers but got a %s and a %s", typeName(args[0]), typeName(args[1]))
+		}
+		return fn(x, y), nil
+	}
+}
@@ -0,0 +1,468 @@
+// Package script implements a small, sandboxed language for transforming Vampire Survivors save files, so rules like
+// "if the cheat code was used, clear it and add 1000 coins" don't require writing Go programs:
+//
+//	# Comments start with a hash.
+//	if CheatCodeUsed {
+//		CheatCodeUsed = false
+//		Coins = Coins + 1000
+//	}
+//	UnlockedWeapons = add(UnlockedWeapons, "KNIFE", "AXE")
+//	for enemy in keys(KillCount) {
+//		KillCount[enemy] = max(KillCount[enemy], 100)
+//	}
+//
+// Fields of the save file are available as variables named like the fields of vampires.SaveFile. Numbers are float64,
+// unlock lists are lists of strings and counters are maps of strings to numbers, whose missing entries read as zero.
+// Local variables are declared using `var name = value`.
+//
+// The available functions are len, contains, add and remove for lists and maps, keys for maps, and min, max, floor and
+// abs for numbers. Scripts can't access anything but the save file and are limited in the number of steps they take as
+// well as in the size of the values they create.
+package script
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// DefaultStepLimit is the number of steps a Program may take if its StepLimit is not set.
+const DefaultStepLimit = 100000
+
+// DefaultSizeLimit is the size of the values a Program may create if its SizeLimit is not set.
+const DefaultSizeLimit = 4096
+
+// ErrStepLimit is returned by Program.Run if the Program exceeds its step limit.
+var ErrStepLimit = errors.New("script exceeded its step limit")
+
+// ErrSizeLimit is returned by Program.Run if the Program creates a value exceeding its size limit.
+var ErrSizeLimit = errors.New("script exceeded its size limit")
+
+// Value is a value of a script. It holds a bool, a float64, a string, a []string list or a map[string]float64.
+type Value interface{}
+
+// Env provides the variables a script reads and writes, e.g. the fields of a save file.
+type Env interface {
+	// Get returns the value of the variable with the provided name. Lists and maps must be copies, as scripts may
+	// modify them.
+	Get(name string) (Value, bool)
+	// Set assigns a value to the variable with the provided name.
+	Set(name string, value Value) error
+}
+
+// Program is a parsed script.
+type Program struct {
+	// StepLimit limits the number of statements and expressions the Program may evaluate, which keeps scripts from
+	// running away, e.g. by nesting loops over large counters. Zero means DefaultStepLimit.
+	StepLimit int
+	// SizeLimit limits the length of the strings and the number of entries of the lists and maps the Program creates,
+	// which keeps scripts from exhausting the memory, e.g. by doubling a string in a loop. Values read from the Env are
+	// not limited. Zero means DefaultSizeLimit.
+	SizeLimit int
+
+	stmts []stmt
+}
+
+// interpreter holds the state of a running Program.
+type interpreter struct {
+	env       Env
+	locals    map[string]Value
+	steps     int
+	limit     int
+	sizeLimit int
+}
+
+// Run executes the Program, reading and writing the variables of the provided Env. Variables declared by the script
+// using `var` shadow the ones of the Env. Run stops at the first error, assignments made before it are not undone.
+func (p *Program) Run(env Env) error {
+	in := &interpreter{env: env, locals: make(map[string]Value), limit: p.StepLimit, sizeLimit: p.SizeLimit}
+	if in.limit == 0 {
+		in.limit = DefaultStepLimit
+	}
+	if in.sizeLimit == 0 {
+		in.sizeLimit = DefaultSizeLimit
+	}
+	return in.exec(p.stmts)
+}
+
+// step counts a step of the script, returning ErrStepLimit once the limit is exceeded.
+func (in *interpreter) step() error {
+	in.steps++
+	if in.steps > in.limit {
+		return ErrStepLimit
+	}
+	return nil
+}
+
+// checkSize returns ErrSizeLimit if the string, list or map exceeds the size limit.
+func (in *interpreter) checkSize(value Value) error {
+	size := 0
+	switch v := value.(type) {
+	case string:
+		size = len(v)
+	case []string:
+		size = len(v)
+	case map[string]float64:
+		size = len(v)
+	}
+	if size > in.sizeLimit {
+		return ErrSizeLimit
+	}
+	return nil
+}
+
+func (in *interpreter) exec(stmts []stmt) error {
+	for _, s := range stmts {
+		if err := in.step(); err != nil {
+			return err
+		}
+		var err error
+		switch s := s.(type) {
+		case *assign:
+			err = in.assign(s)
+		case *ifStmt:
+			var cond Value
+			if cond, err = in.eval(s.cond); err == nil {
+				b, ok := cond.(bool)
+				switch {
+				case !ok:
+					err = &Error{s.line, fmt.Sprintf("condition must be a bool but is a %s", typeName(cond))}
+				case b:
+					err = in.exec(s.then)
+				default:
+					err = in.exec(s.els)
+				}
+			}
+		case *forStmt:
+			err = in.loop(s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) assign(s *assign) error {
+	value, err := in.eval(s.value)
+	if err != nil {
+		return err
+	}
+	if s.declare {
+		if _, exists := in.lookup(s.name); exists {
+			return &Error{s.line, s.name + " is already declared"}
+		}
+		in.locals[s.name] = value
+		return nil
+	}
+
+	current, exists := in.lookup(s.name)
+	if !exists {
+		return &Error{s.line, "undefined variable " + s.name}
+	}
+	if s.key != nil {
+		m, ok := current.(map[string]float64)
+		if !ok {
+			return &Error{s.line, fmt.Sprintf("can't assign to entries of a %s", typeName(current))}
+		}
+		key, err := in.eval(s.key)
+		if err != nil {
+			return err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return &Error{s.line, fmt.Sprintf("map keys must be strings but got a %s", typeName(key))}
+		}
+		n, ok := value.(float64)
+		if !ok {
+			return &Error{s.line, fmt.Sprintf("map values must be numbers but got a %s", typeName(value))}
+		}
+		updated := copyMap(m)
+		updated[k] = n
+		if err := in.checkSize(updated); err != nil {
+			return err
+		}
+		value = updated
+	} else if typeName(current) != typeName(value) {
+		return &Error{s.line, fmt.Sprintf("can't assign a %s to %s, which is a %s", typeName(value), s.name,
+			typeName(current))}
+	}
+
+	if _, local := in.locals[s.name]; local {
+		in.locals[s.name] = value
+		return nil
+	}
+	if err := in.env.Set(s.name, value); err != nil {
+		return &Error{s.line, err.Error()}
+	}
+	return nil
+}
+
+func (in *interpreter) loop(s *forStmt) error {
+	x, err := in.eval(s.x)
+	if err != nil {
+		return err
+	}
+	var items []string
+	switch x := x.(type) {
+	case []string:
+		items = x
+	case map[string]float64:
+		items = sortedKeys(x)
+	default:
+		return &Error{s.line, fmt.Sprintf("can't loop over a %s", typeName(x))}
+	}
+	if _, exists := in.lookup(s.name); exists {
+		return &Error{s.line, s.name + " is already declared"}
+	}
+
+	defer delete(in.locals, s.name)
+	for _, item := range items {
+		in.locals[s.name] = item
+		if err := in.exec(s.body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a local variable or, if there is none, of a variable of the Env.
+func (in *interpreter) lookup(name string) (Value, bool) {
+	if value, ok := in.locals[name]; ok {
+		return value, true
+	}
+	return in.env.Get(name)
+}
+
+func (in *interpreter) eval(x expr) (Value, error) {
+	if err := in.step(); err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case *literal:
+		return x.value, nil
+	case *listLiteral:
+		list := make([]string, 0, len(x.elems))
+		for _, elem := range x.elems {
+			value, err := in.eval(elem)
+			if err != nil {
+				return nil, err
+			}
+			s, ok := value.(string)
+			if !ok {
+				return nil, &Error{x.line, fmt.Sprintf("lists may only contain strings but got a %s",
+					typeName(value))}
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	case *ident:
+		value, ok := in.lookup(x.name)
+		if !ok {
+			return nil, &Error{x.line, "undefined variable " + x.name}
+		}
+		return value, nil
+	case *index:
+		return in.index(x)
+	case *call:
+		fn, ok := builtins[x.name]
+		if !ok {
+			return nil, &Error{x.line, "undefined function " + x.name}
+		}
+		args := make([]Value, len(x.args))
+		for i, arg := range x.args {
+			value, err := in.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		value, err := fn(args)
+		if err != nil {
+			return nil, &Error{x.line, fmt.Sprintf("%s: %v", x.name, err)}
+		}
+		if err := in.checkSize(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case *unary:
+		value, err := in.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case float64:
+			if x.op == "-" {
+				return -v, nil
+			}
+		case bool:
+			if x.op == "!" {
+				return !v, nil
+			}
+		}
+		return nil, &Error{x.line, fmt.Sprintf("invalid operation %s on a %s", x.op, typeName(value))}
+	case *binary:
+		return in.binary(x)
+	}
+	panic(fmt.Sprintf("script: unknown expression %T", x))
+}
+
+func (in *interpreter) index(x *index) (Value, error) {
+	value, err := in.eval(x.x)
+	if err != nil {
+		return nil, err
+	}
+	key, err := in.eval(x.key)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case []string:
+		i, ok := key.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, &Error{x.line, "list indices must be integers"}
+		}
+		if i < 0 || int(i) >= len(v) {
+			return nil, &Error{x.line, fmt.Sprintf("index %v out of range of list of length %d", i, len(v))}
+		}
+		return v[int(i)], nil
+	case map[string]float64:
+		k, ok := key.(string)
+		if !ok {
+			return nil, &Error{x.line, fmt.Sprintf("map keys must be strings but got a %s", typeName(key))}
+		}
+		// Like counters of the game, missing entries count as zero.
+		return v[k], nil
+	}
+	return nil, &Error{x.line, fmt.Sprintf("can't index a %s", typeName(value))}
+}
+
+func (in *interpreter) binary(x *binary) (Value, error) {
+	left, err := in.eval(x.x)
+	if err != nil {
+		return nil, err
+	}
+
+	// The logical operators short-circuit.
+	if x.op == "&&" || x.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, &Error{x.line, fmt.Sprintf("invalid operation %s on a %s", x.op, typeName(left))}
+		}
+		if l == (x.op == "||") {
+			return l, nil
+		}
+		right, err := in.eval(x.y)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := right.(bool); !ok {
+			return nil, &Error{x.line, fmt.Sprintf("invalid operation %s on a %s", x.op, typeName(right))}
+		}
+		return right, nil
+	}
+
+	right, err := in.eval(x.y)
+	if err != nil {
+		return nil, err
+	}
+	switch x.op {
+	case "==":
+		return reflect.DeepEqual(left, right), nil
+	case "!=":
+		return !reflect.DeepEqual(left, right), nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			return arithmetic(x, l, r)
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			switch x.op {
+			case "+":
+				// Check the size before concatenating, so the string is never allocated.
+				if len(l)+len(r) > in.sizeLimit {
+					return nil, ErrSizeLimit
+				}
+				return l + r, nil
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			}
+		}
+	}
+	return nil, &Error{x.line, fmt.Sprintf("invalid operation %s on a %s and a %s", x.op, typeName(left),
+		typeName(right))}
+}
+
+// arithmetic applies the operator of the binary expression to two numbers.
+func arithmetic(x *binary, l, r float64) (Value, error) {
+	switch x.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, &Error{x.line, "division by zero"}
+		}
+		if x.op == "%" {
+			return math.Mod(l, r), nil
+		}
+		return l / r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+// typeName returns the name of the type of the value used in error messages.
+func typeName(value Value) string {
+	switch value.(type) {
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []string:
+		return "list"
+	case map[string]float64:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func copyMap(m map[string]float64) map[string]float64 {
+	c := make(map[string]float64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
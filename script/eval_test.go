@@ -0,0 +1,126 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mapEnv is an Env backed by a map.
+type mapEnv map[string]Value
+
+func (e mapEnv) Get(name string) (Value, bool) {
+	value, ok := e[name]
+	return value, ok
+}
+
+func (e mapEnv) Set(name string, value Value) error {
+	e[name] = value
+	return nil
+}
+
+func run(t *testing.T, src string, env mapEnv) error {
+	program, err := Parse(src)
+	assert.NoError(t, err)
+	return program.Run(env)
+}
+
+func Test_ProgramRun(t *testing.T) {
+	env := mapEnv{
+		"n":     2.0,
+		"s":     "a",
+		"flag":  true,
+		"list":  []string{"A", "B"},
+		"count": map[string]float64{"X": 1},
+	}
+	err := run(t, `
+		var local = n * 10 % 7
+		n = local + 1
+		s = s + "b"
+		if flag && len(list) == 2 || missing {
+			flag = false
+		}
+		list = remove(add(list, "C", ["A", "D"]), "B")
+		for key in ["X", "Y"] {
+			count[key] = count[key] + 1
+		}
+		if contains(list, list[0]) && !contains(count, "Z") && "a" < s {
+			count = remove(count, "X")
+		}
+	`, env)
+	assert.NoError(t, err)
+	assert.Equal(t, mapEnv{
+		"n":     7.0,
+		"s":     "ab",
+		"flag":  false,
+		"list":  []string{"A", "C", "D"},
+		"count": map[string]float64{"Y": 1},
+	}, env)
+}
+
+func Test_ProgramRunErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		"n = s":                   "line 1: can't assign a string to n, which is a number",
+		"unknown = 1":             "line 1: undefined variable unknown",
+		"n = unknown":             "line 1: undefined variable unknown",
+		"var n = 1":               "line 1: n is already declared",
+		"if n { }":                "line 1: condition must be a bool but is a number",
+		"n = n / 0":               "line 1: division by zero",
+		"n = n + s":               "line 1: invalid operation + on a number and a string",
+		"n = -s":                  "line 1: invalid operation - on a string",
+		"n = len(1)":              "line 1: len: can't get the length of a number",
+		"n = nope()":              "line 1: undefined function nope",
+		"s = list[2]":             "line 1: index 2 out of range of list of length 1",
+		"n[\"a\"] = 1":            "line 1: can't assign to entries of a number",
+		"for x in n { }":          "line 1: can't loop over a number",
+		"list = [1]":              "line 1: lists may only contain strings but got a number",
+		"\n\nlist = add(list, 1)": "line 3: add: expected a string or a list but got a number",
+	} {
+		err := run(t, src, mapEnv{"n": 1.0, "s": "a", "list": []string{"A"}})
+		assert.EqualError(t, err, msg, src)
+	}
+}
+
+func Test_ProgramRunStepLimit(t *testing.T) {
+	env := mapEnv{"n": 0.0, "list": []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"}}
+	program, err := Parse(`
+		for a in list { for b in list { for c in list {
+			n = n + 1
+		} } }
+	`)
+	assert.NoError(t, err)
+
+	program.StepLimit = 1000
+	assert.ErrorIs(t, program.Run(env), ErrStepLimit)
+
+	env["n"] = 0.0
+	program.StepLimit = 0
+	assert.NoError(t, program.Run(env))
+	assert.Equal(t, 1000.0, env["n"])
+}
+
+func Test_ProgramRunSizeLimit(t *testing.T) {
+	for _, src := range []string{
+		`for i in list { s = s + s }`,
+		`for i in list { list = add(list, list[0] + i) }`,
+		`for i in list { count[i + s] = 1 }`,
+	} {
+		env := mapEnv{"s": "ab", "list": []string{"A", "B", "C", "D", "E"}, "count": map[string]float64{}}
+		program, err := Parse(src)
+		assert.NoError(t, err)
+
+		program.SizeLimit = 4
+		assert.ErrorIs(t, program.Run(env), ErrSizeLimit, src)
+
+		program.SizeLimit = 0
+		assert.NoError(t, program.Run(env), src)
+	}
+
+	// Values read from the Env are not limited.
+	program, err := Parse(`n = len(s)`)
+	assert.NoError(t, err)
+	program.SizeLimit = 1
+	env := mapEnv{"n": 0.0, "s": "abc"}
+	assert.NoError(t, program.Run(env))
+	assert.Equal(t, 3.0, env["n"])
+}
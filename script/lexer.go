@@ -0,0 +1,119 @@
+package script
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind defines the kind of a token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenKeyword
+	tokenOperator
+)
+
+// keywords contains the identifiers reserved by the language.
+var keywords = map[string]bool{
+	"if": true, "else": true, "for": true, "in": true, "var": true, "true": true, "false": true,
+}
+
+// operators contains the operators and punctuation of the language. Operators of two characters must be listed first,
+// so that they take precedence over their prefixes.
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "{", "}", "[", "]", ",",
+}
+
+// token is a lexical token of a script.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lex splits the source of a script into tokens, terminated by a token of kind tokenEOF. Comments start with `#` and
+// last until the end of the line.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ';':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case isLetter(c):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			kind := tokenIdent
+			if keywords[src[start:i]] {
+				kind = tokenKeyword
+			}
+			tokens = append(tokens, token{kind, src[start:i], line})
+		case isDigit(c):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			if _, err := strconv.ParseFloat(src[start:i], 64); err != nil {
+				return nil, &Error{line, "invalid number " + src[start:i]}
+			}
+			tokens = append(tokens, token{tokenNumber, src[start:i], line})
+		case c == '"':
+			start := i
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && src[i] == '\n' {
+					return nil, &Error{line, "unterminated string"}
+				}
+			}
+			if i >= len(src) {
+				return nil, &Error{line, "unterminated string"}
+			}
+			i++
+			value, err := strconv.Unquote(src[start:i])
+			if err != nil {
+				return nil, &Error{line, "invalid string " + src[start:i]}
+			}
+			tokens = append(tokens, token{tokenString, value, line})
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &Error{line, "unexpected character " + strconv.QuoteRune(rune(c))}
+			}
+			tokens = append(tokens, token{tokenOperator, op, line})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{tokenEOF, "", line}), nil
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || unicode.IsLetter(rune(c)) && c < unicode.MaxASCII
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,371 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Error describes a syntax or runtime error of a script.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// expr is an expression of a script.
+type expr interface{}
+
+type (
+	literal struct {
+		value Value
+	}
+	listLiteral struct {
+		line  int
+		elems []expr
+	}
+	ident struct {
+		line int
+		name string
+	}
+	index struct {
+		line int
+		x    expr
+		key  expr
+	}
+	call struct {
+		line int
+		name string
+		args []expr
+	}
+	unary struct {
+		line int
+		op   string
+		x    expr
+	}
+	binary struct {
+		line int
+		op   string
+		x, y expr
+	}
+)
+
+// stmt is a statement of a script.
+type stmt interface{}
+
+type (
+	// assign assigns a value to a variable or, if key is set, to an entry of a map.
+	assign struct {
+		line    int
+		name    string
+		key     expr
+		value   expr
+		declare bool
+	}
+	ifStmt struct {
+		line      int
+		cond      expr
+		then, els []stmt
+	}
+	forStmt struct {
+		line int
+		name string
+		x    expr
+		body []stmt
+	}
+)
+
+// parser is a recursive descent parser of scripts.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses the source of a script into a Program.
+func Parse(src string) (*Program, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	var stmts []stmt
+	for p.peek().kind != tokenEOF {
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+	}
+	return &Program{stmts: stmts}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the provided operator or keyword.
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokenOperator || t.kind == tokenKeyword) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected("expected " + text)
+	}
+	return nil
+}
+
+// unexpected returns an error describing the next token.
+func (p *parser) unexpected(msg string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return &Error{t.line, msg + " but got end of script"}
+	}
+	return &Error{t.line, fmt.Sprintf("%s but got %q", msg, t.text)}
+}
+
+func (p *parser) statement() (stmt, error) {
+	line := p.peek().line
+	switch {
+	case p.accept("if"):
+		return p.ifStatement(line)
+	case p.accept("for"):
+		if p.peek().kind != tokenIdent {
+			return nil, p.unexpected("expected loop variable")
+		}
+		name := p.next()
+		if err := p.expect("in"); err != nil {
+			return nil, err
+		}
+		x, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &forStmt{line, name.text, x, body}, nil
+	}
+
+	declare := p.accept("var")
+	if p.peek().kind != tokenIdent {
+		return nil, p.unexpected("expected statement")
+	}
+	name := p.next()
+	s := &assign{line: line, name: name.text, declare: declare}
+	if !declare && p.accept("[") {
+		key, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		s.key = key
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	s.value = value
+	return s, nil
+}
+
+func (p *parser) ifStatement(line int) (stmt, error) {
+	cond, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	s := &ifStmt{line: line, cond: cond, then: then}
+	if p.accept("else") {
+		if elseLine := p.peek().line; p.accept("if") {
+			elseIf, err := p.ifStatement(elseLine)
+			if err != nil {
+				return nil, err
+			}
+			s.els = []stmt{elseIf}
+		} else if s.els, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// block parses statements enclosed in braces.
+func (p *parser) block() ([]stmt, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var stmts []stmt
+	for !p.accept("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.unexpected("expected }")
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, s)
+	}
+	return stmts, nil
+}
+
+// precedences lists the binary operators from the lowest to the highest precedence.
+var precedences = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) expression() (expr, error) {
+	return p.binary(0)
+}
+
+func (p *parser) binary(level int) (expr, error) {
+	if level == len(precedences) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		matched := false
+		for _, op := range precedences[level] {
+			if t.kind == tokenOperator && t.text == op {
+				matched = true
+			}
+		}
+		if !matched {
+			return x, nil
+		}
+		p.next()
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{t.line, t.text, x, y}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	t := p.peek()
+	if p.accept("-") || p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{t.line, t.text, x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("[") {
+		key, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		x = &index{p.tokens[p.pos-1].line, x, key}
+	}
+	return x, nil
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	p.pos++
+	switch t.kind {
+	case tokenNumber:
+		value, _ := strconv.ParseFloat(t.text, 64)
+		return &literal{value}, nil
+	case tokenString:
+		return &literal{t.text}, nil
+	case tokenKeyword:
+		switch t.text {
+		case "true":
+			return &literal{true}, nil
+		case "false":
+			return &literal{false}, nil
+		}
+	case tokenIdent:
+		if !p.accept("(") {
+			return &ident{t.line, t.text}, nil
+		}
+		c := &call{line: t.line, name: t.text}
+		args, err := p.list(")")
+		if err != nil {
+			return nil, err
+		}
+		c.args = args
+		return c, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			x, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			elems, err := p.list("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listLiteral{t.line, elems}, nil
+		}
+	}
+	p.pos--
+	return nil, p.unexpected("expected expression")
+}
+
+// list parses comma-separated expressions terminated by the provided operator.
+func (p *parser) list(end string) ([]expr, error) {
+	var exprs []expr
+	for !p.accept(end) {
+		if len(exprs) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		x, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, x)
+	}
+	return exprs, nil
+}
@@ -0,0 +1,80 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lex(t *testing.T) {
+	tokens, err := lex("if Coins >= 10 { # comment\n\tName = \"a\\\"b\" }")
+	assert.NoError(t, err)
+
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.text)
+	}
+	assert.Equal(t, []string{"if", "Coins", ">=", "10", "{", "Name", "=", `a"b`, "}", ""}, texts)
+	assert.Equal(t, tokenKeyword, tokens[0].kind)
+	assert.Equal(t, tokenString, tokens[7].kind)
+	assert.Equal(t, 2, tokens[5].line)
+	assert.Equal(t, tokenEOF, tokens[9].kind)
+}
+
+func Test_lexErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		`Name = "abc`:   "line 1: unterminated string",
+		"Coins = 1.2.3": "line 1: invalid number 1.2.3",
+		"\nCoins = $":   "line 2: unexpected character '$'",
+	} {
+		_, err := lex(src)
+		assert.EqualError(t, err, msg, src)
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	program, err := Parse(`
+		var total = 1 + 2 * 3
+		if !CheatCodeUsed && total > 0 {
+			Coins = -Coins
+		} else if Coins == 0 {
+			KillCount["BAT1"] = KillCount["BAT1"] + 1
+		} else {
+			UnlockedWeapons = add(UnlockedWeapons, ["A", "B"])
+		}
+		for id in UnlockedStages {
+			Coins = Coins + len(id)
+		}
+	`)
+	assert.NoError(t, err)
+	assert.Len(t, program.stmts, 3)
+
+	decl := program.stmts[0].(*assign)
+	assert.True(t, decl.declare)
+	assert.Equal(t, &binary{2, "+", &literal{1.0}, &binary{2, "*", &literal{2.0}, &literal{3.0}}}, decl.value)
+
+	cond := program.stmts[1].(*ifStmt)
+	assert.Equal(t, "&&", cond.cond.(*binary).op)
+	assert.Equal(t, &unary{4, "-", &ident{4, "Coins"}}, cond.then[0].(*assign).value)
+	assert.Equal(t, &literal{"BAT1"}, cond.els[0].(*ifStmt).then[0].(*assign).key)
+
+	loop := program.stmts[2].(*forStmt)
+	assert.Equal(t, "id", loop.name)
+	assert.Len(t, loop.body, 1)
+}
+
+func Test_ParseErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		"Coins =":              "line 1: expected expression but got end of script",
+		"Coins 1":              `line 1: expected = but got "1"`,
+		"if Coins > 1 Coins":   `line 1: expected { but got "Coins"`,
+		"if true {\nCoins = 1": "line 2: expected } but got end of script",
+		"var x[1] = 2":         `line 1: expected = but got "["`,
+		"for 1 in x {}":        `line 1: expected loop variable but got "1"`,
+		"f(1,)":                `line 1: expected = but got "("`,
+		"x = f(1,)":            `line 1: expected expression but got ")"`,
+	} {
+		_, err := Parse(src)
+		assert.EqualError(t, err, msg, src)
+	}
+}
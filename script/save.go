@@ -0,0 +1,99 @@
+package script
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+// saveEnv is the Env of a SaveFile.
+type saveEnv struct {
+	save *vampires.SaveFile
+}
+
+// NewSaveEnv returns an Env exposing the fields of the SaveFile as variables.
+func NewSaveEnv(save *vampires.SaveFile) Env {
+	return saveEnv{save}
+}
+
+// Get implements Env.
+func (e saveEnv) Get(name string) (Value, bool) {
+	field, ok := e.field(name)
+	if !ok {
+		return nil, false
+	}
+	switch v := field.Value.Interface().(type) {
+	case []string:
+		return append([]string{}, v...), true
+	case map[string]int32:
+		m := make(map[string]float64, len(v))
+		for k, n := range v {
+			m[k] = float64(n)
+		}
+		return m, true
+	}
+	switch field.Value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return field.Value.Float(), true
+	case reflect.Int32, reflect.Int64:
+		return float64(field.Value.Int()), true
+	}
+	return field.Value.Interface(), true
+}
+
+// Set implements Env.
+func (e saveEnv) Set(name string, value Value) error {
+	field, ok := e.field(name)
+	if !ok {
+		return fmt.Errorf("undefined variable %s", name)
+	}
+	if field.ReadOnly {
+		return fmt.Errorf("%s is read-only", name)
+	}
+	switch v := value.(type) {
+	case []string:
+		field.Value.Set(reflect.ValueOf(append([]string{}, v...)))
+	case map[string]float64:
+		m := make(map[string]int32, len(v))
+		for k, n := range v {
+			i, err := integer(n, 32)
+			if err != nil {
+				return fmt.Errorf("%s of %s %w", k, name, err)
+			}
+			m[k] = int32(i)
+		}
+		field.Value.Set(reflect.ValueOf(m))
+	case float64:
+		switch field.Value.Kind() {
+		case reflect.Int32, reflect.Int64:
+			i, err := integer(v, field.Value.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("%s %w", name, err)
+			}
+			field.Value.SetInt(i)
+		default:
+			field.Value.SetFloat(v)
+		}
+	default:
+		field.Value.Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// field returns the field of the SaveFile with the provided name. Unlike SaveFile.Field, it doesn't accept keys, as
+// these aren't valid identifiers anyway.
+func (e saveEnv) field(name string) (vampires.Field, bool) {
+	field, ok := e.save.Field(name)
+	return field, ok && field.Name == name
+}
+
+// integer converts the number to an integer of the provided size, failing if it has a fractional part or overflows.
+func integer(n float64, bits int) (int64, error) {
+	limit := math.Ldexp(1, bits-1)
+	if n != math.Trunc(n) || n < -limit || n >= limit {
+		return 0, fmt.Errorf("must be an integer of %d bits but is %v", bits, n)
+	}
+	return int64(n), nil
+}
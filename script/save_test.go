@@ -0,0 +1,49 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SaveEnv(t *testing.T) {
+	save := &vampires.SaveFile{
+		CheatCodeUsed: true,
+		Coins:         10,
+		BLuck:         1,
+		KillCount:     map[string]int32{"BAT1": 5},
+	}
+	program, err := Parse(`
+		if CheatCodeUsed {
+			CheatCodeUsed = false
+			Coins = Coins + 1000
+		}
+		BLuck = BLuck * 2
+		UnlockedWeapons = add(UnlockedWeapons, "KNIFE")
+		KillCount["BAT1"] = KillCount["BAT1"] + 1
+		Language = "de"
+	`)
+	assert.NoError(t, err)
+	assert.NoError(t, program.Run(NewSaveEnv(save)))
+
+	assert.False(t, save.CheatCodeUsed)
+	assert.Equal(t, 1010.0, save.Coins)
+	assert.Equal(t, int32(2), save.BLuck)
+	assert.Equal(t, []string{"KNIFE"}, save.UnlockedWeapons)
+	assert.Equal(t, map[string]int32{"BAT1": 6}, save.KillCount)
+	assert.Equal(t, "de", save.Language)
+}
+
+func Test_SaveEnvErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		"BLuck = 1.5":                      "line 1: BLuck must be an integer of 32 bits but is 1.5",
+		"KillCount[\"BAT1\"] = 3000000000": "line 1: BAT1 of KillCount must be an integer of 32 bits but is 3e+09",
+		"Coins = CapacitorStorage":         "line 1: undefined variable CapacitorStorage",
+		"LifetimeCoins = 0":                "line 1: LifetimeCoins is read-only",
+	} {
+		program, err := Parse(src)
+		assert.NoError(t, err)
+		assert.EqualError(t, program.Run(NewSaveEnv(new(vampires.SaveFile))), msg, src)
+	}
+}
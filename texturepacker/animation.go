@@ -0,0 +1,191 @@
+package texturepacker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// NamingRule extracts the name of the animation and the index within it from the file name of a frame. It reports
+// false if the frame is not part of an animation.
+type NamingRule func(fileName string) (name string, index int, ok bool)
+
+// DefaultNamingRules are used by Animations if no rules are provided. They match the names used by the game, e.g.
+// `Antonio_01.png` and `Bat12.png`.
+var DefaultNamingRules = []NamingRule{
+	PatternRule(regexp.MustCompile(`^(.*)_(\d+)\.[^./]+$`)),
+	PatternRule(regexp.MustCompile(`^(.*?)(\d+)\.[^./]+$`)),
+}
+
+// PatternRule returns a NamingRule matching file names against the regular expression, whose first submatch is the name
+// of the animation and whose second submatch is the decimal index of the frame.
+func PatternRule(exp *regexp.Regexp) NamingRule {
+	return func(fileName string) (string, int, bool) {
+		parts := exp.FindStringSubmatch(fileName)
+		if len(parts) < 3 {
+			return "", 0, false
+		}
+		index, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return "", 0, false
+		}
+		return parts[1], index, true
+	}
+}
+
+// Animation is a sequence of frames of a sheet sharing a name, e.g. `Antonio_01.png`, `Antonio_02.png` and so on.
+type Animation struct {
+	Name string
+	// Frames holds the frames ordered by their index.
+	Frames []SheetFrame
+	// Indices holds the index of each of the Frames.
+	Indices []int
+	// Missing lists the ranges of indices between the first and the last frame which are not part of the sheet, which
+	// usually means that a frame has been lost or misnamed.
+	Missing []IndexRange
+	// Duplicates lists the indices shared by several frames, e.g. by `Bat1.png` and `Bat_01.png`. All of these frames
+	// are kept in Frames, in the order of the sheet.
+	Duplicates []int
+}
+
+// IndexRange is an inclusive range of indices of the frames of an Animation.
+type IndexRange struct {
+	First, Last int
+}
+
+// String returns the range in the form `3-9`, or just the index if the range holds a single one.
+func (r IndexRange) String() string {
+	if r.First == r.Last {
+		return strconv.Itoa(r.First)
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// Animations groups the frames of the sheet into animations using the provided rules, which are tried in order until
+// one matches. DefaultNamingRules are used if none are provided. Frames not matched by any rule are left out. The
+// animations are ordered by name, comparing numbers within the names by value.
+func Animations(sheet *Sheet, rules ...NamingRule) []Animation {
+	if len(rules) == 0 {
+		rules = DefaultNamingRules
+	}
+
+	byName := make(map[string]*Animation)
+	var names []string
+	for _, frame := range sheet.Frames() {
+		for _, rule := range rules {
+			name, index, ok := rule(frame.FileName)
+			if !ok {
+				continue
+			}
+			anim, exists := byName[name]
+			if !exists {
+				anim = &Animation{Name: name}
+				byName[name] = anim
+				names = append(names, name)
+			}
+			anim.Frames = append(anim.Frames, frame)
+			anim.Indices = append(anim.Indices, index)
+			break
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return naturalLess(names[i], names[j])
+	})
+	animations := make([]Animation, 0, len(names))
+	for _, name := range names {
+		anim := byName[name]
+		anim.sort()
+		animations = append(animations, *anim)
+	}
+	return animations
+}
+
+// sort orders the frames of the Animation by their index and determines the missing and duplicate ones.
+func (a *Animation) sort() {
+	sort.Stable(animationFrames{a})
+	for i := 1; i < len(a.Indices); i++ {
+		previous, index := a.Indices[i-1], a.Indices[i]
+		switch {
+		case index == previous:
+			if n := len(a.Duplicates); n == 0 || a.Duplicates[n-1] != index {
+				a.Duplicates = append(a.Duplicates, index)
+			}
+		case index > previous+1:
+			a.Missing = append(a.Missing, IndexRange{previous + 1, index - 1})
+		}
+	}
+}
+
+// animationFrames sorts the frames of an Animation along with their indices.
+type animationFrames struct {
+	*Animation
+}
+
+func (f animationFrames) Len() int {
+	return len(f.Frames)
+}
+
+func (f animationFrames) Less(i, j int) bool {
+	return f.Indices[i] < f.Indices[j]
+}
+
+func (f animationFrames) Swap(i, j int) {
+	f.Frames[i], f.Frames[j] = f.Frames[j], f.Frames[i]
+	f.Indices[i], f.Indices[j] = f.Indices[j], f.Indices[i]
+}
+
+// naturalLess compares the strings like strings do, except for sequences of digits, which are compared by their value,
+// so `Bat2` comes before `Bat10`. Numbers differing only in leading zeros are ordered by their length if the strings
+// are equal otherwise.
+func naturalLess(a, b string) bool {
+	zeros := 0
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, nb := digitPrefix(a), digitPrefix(b)
+			// Compare the values by their length without leading zeros first, which avoids overflows.
+			va, vb := trimZeros(na), trimZeros(nb)
+			if len(va) != len(vb) {
+				return len(va) < len(vb)
+			}
+			if va != vb {
+				return va < vb
+			}
+			if zeros == 0 {
+				zeros = len(na) - len(nb)
+			}
+			a, b = a[len(na):], b[len(nb):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	if len(a) == len(b) {
+		return zeros < 0
+	}
+	return len(a) < len(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// digitPrefix returns the leading digits of the string.
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+// trimZeros removes leading zeros from the digits, keeping at least one digit.
+func trimZeros(digits string) string {
+	for len(digits) > 1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+	return digits
+}
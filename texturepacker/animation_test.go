@@ -0,0 +1,87 @@
+package texturepacker
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// animationTestSheet returns a sheet whose frames are named like the provided file names.
+func animationTestSheet(names ...string) *Sheet {
+	texture := PackedTexture{Image: "sheet.png"}
+	for _, name := range names {
+		texture.Frames = append(texture.Frames, Frame{FileName: name})
+	}
+	return &Sheet{Textures: []PackedTexture{texture}}
+}
+
+// frameNames returns the file names of the frames of the Animation.
+func frameNames(anim Animation) []string {
+	var names []string
+	for _, frame := range anim.Frames {
+		names = append(names, frame.FileName)
+	}
+	return names
+}
+
+func Test_Animations(t *testing.T) {
+	sheet := animationTestSheet("Antonio_10.png", "Antonio_02.png", "Bat10.png", "Antonio_01.png", "Bat2.png",
+		"Background.png", "Antonio_200.png", "Bat3.png")
+	animations := Animations(sheet)
+	assert.Len(t, animations, 2)
+
+	assert.Equal(t, "Antonio", animations[0].Name)
+	assert.Equal(t, []string{"Antonio_01.png", "Antonio_02.png", "Antonio_10.png", "Antonio_200.png"},
+		frameNames(animations[0]))
+	assert.Equal(t, []int{1, 2, 10, 200}, animations[0].Indices)
+	assert.Equal(t, []IndexRange{{3, 9}, {11, 199}}, animations[0].Missing)
+	assert.Empty(t, animations[0].Duplicates)
+
+	assert.Equal(t, "Bat", animations[1].Name)
+	assert.Equal(t, []string{"Bat2.png", "Bat3.png", "Bat10.png"}, frameNames(animations[1]))
+	assert.Equal(t, []IndexRange{{4, 9}}, animations[1].Missing)
+	assert.Equal(t, "sheet.png", animations[1].Frames[0].Texture.Image)
+}
+
+func Test_AnimationsDuplicates(t *testing.T) {
+	sheet := animationTestSheet("Bat1.png", "Bat_01.png", "Bat2.png", "Bat_02.png", "Bat002.png", "Bat3.png")
+	animations := Animations(sheet)
+	assert.Len(t, animations, 1)
+	assert.Equal(t, []string{"Bat1.png", "Bat_01.png", "Bat2.png", "Bat_02.png", "Bat002.png", "Bat3.png"},
+		frameNames(animations[0]))
+	assert.Equal(t, []int{1, 2}, animations[0].Duplicates)
+	assert.Empty(t, animations[0].Missing)
+}
+
+func Test_IndexRangeString(t *testing.T) {
+	assert.Equal(t, "3", IndexRange{3, 3}.String())
+	assert.Equal(t, "3-9", IndexRange{3, 9}.String())
+}
+
+func Test_AnimationsCustomRule(t *testing.T) {
+	sheet := animationTestSheet("walk-frame3.png", "walk-frame1.png", "idle-frame1.png", "walk_2.png")
+	animations := Animations(sheet, PatternRule(regexp.MustCompile(`^(.*)-frame(\d+)\.png$`)))
+	assert.Len(t, animations, 2)
+	assert.Equal(t, "idle", animations[0].Name)
+	assert.Equal(t, []string{"walk-frame1.png", "walk-frame3.png"}, frameNames(animations[1]))
+	assert.Equal(t, []IndexRange{{2, 2}}, animations[1].Missing)
+
+	// Rules are tried in order.
+	byPrefix := func(fileName string) (string, int, bool) {
+		return fileName[:4], 0, true
+	}
+	animations = Animations(sheet, PatternRule(regexp.MustCompile(`^(idle)-frame(\d+)`)), byPrefix)
+	assert.Equal(t, []string{"idle", "walk"}, []string{animations[0].Name, animations[1].Name})
+	assert.Len(t, animations[1].Frames, 3)
+}
+
+func Test_naturalLess(t *testing.T) {
+	assert.True(t, naturalLess("Bat2", "Bat10"))
+	assert.False(t, naturalLess("Bat10", "Bat2"))
+	assert.True(t, naturalLess("a01b", "a1c"))
+	assert.True(t, naturalLess("a1", "a01"))
+	assert.True(t, naturalLess("a", "a1"))
+	assert.True(t, naturalLess("a99999999999999999999", "a100000000000000000000"))
+	assert.False(t, naturalLess("a", "a"))
+}
@@ -0,0 +1,413 @@
+package texturepacker
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Format names a sprite sheet format of another engine or tool which sheets can be exported to.
+type Format string
+
+const (
+	// FormatPhaser is the JSON hash layout read by Phaser's atlas loader, written for every texture.
+	FormatPhaser Format = "phaser"
+	// FormatStarling is the TextureAtlas XML of Starling and Sparrow, written for every texture.
+	FormatStarling Format = "starling"
+	// FormatAseprite is the JSON array layout of Aseprite's sprite sheet export, written for every texture.
+	FormatAseprite Format = "aseprite"
+	// FormatGodot is a Godot 4 AtlasTexture resource, written for every frame. AtlasTexture doesn't support rotated
+	// frames.
+	FormatGodot Format = "godot"
+	// FormatCSS is a style sheet with a class for every frame, written for every texture.
+	FormatCSS Format = "css"
+)
+
+// Formats lists all formats sheets can be exported to.
+var Formats = []Format{FormatPhaser, FormatStarling, FormatAseprite, FormatGodot, FormatCSS}
+
+// exporter writes the files of a Format. Either texture or frame is set, depending on whether the Format describes a
+// whole texture or a single frame per file. If set, check is called for every frame before any file is written.
+type exporter struct {
+	ext     string
+	texture func(w io.Writer, texture PackedTexture) error
+	frame   func(w io.Writer, texture PackedTexture, frame Frame) error
+	check   func(frame Frame) error
+}
+
+// The extensions of the formats describing textures name the format, so they neither collide with each other nor with
+// the JSON of the exported sheet.
+var exporters = map[Format]exporter{
+	FormatPhaser:   {ext: ".phaser.json", texture: exportPhaser},
+	FormatStarling: {ext: ".starling.xml", texture: exportStarling},
+	FormatAseprite: {ext: ".aseprite.json", texture: exportAseprite},
+	FormatGodot:    {ext: ".tres", frame: exportGodot, check: checkGodot},
+	FormatCSS:      {ext: ".css", texture: exportCSS},
+}
+
+// exportFile is a file to be written by Export.
+type exportFile struct {
+	path   string
+	source string
+	write  func(w io.Writer) error
+}
+
+// Export writes the Sheet in the provided Format into the directory and returns the paths of the written files. The
+// files are named after the images of the textures or, for formats describing single frames, after the frames. The
+// images of the textures are copied into the directory if it is not the one of the Sheet.
+//
+// No file is written if the Sheet can't be exported, e.g. because it has frames the Format does not support or frames
+// whose files would be named the same.
+func (s *Sheet) Export(format Format, dir string) ([]string, error) {
+	files, err := s.exportFiles(format, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, file := range files {
+		if err := writeFile(file.path, file.write); err != nil {
+			return nil, err
+		}
+		written = append(written, file.path)
+	}
+	return written, nil
+}
+
+// exportFiles plans the files Export writes, returning an error if the Sheet can't be exported.
+func (s *Sheet) exportFiles(format Format, dir string) ([]exportFile, error) {
+	exp, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+
+	var files []exportFile
+	sources := make(map[string]string)
+	add := func(file exportFile) error {
+		if source, ok := sources[file.path]; ok {
+			return fmt.Errorf("%s and %s would both be exported to %s", source, file.source, file.path)
+		}
+		sources[file.path] = file.source
+		files = append(files, file)
+		return nil
+	}
+
+	copyImages := !sameDir(dir, s.dir)
+	for i := range s.Textures {
+		texture := &s.Textures[i]
+		target, err := exportPath(dir, texture.Image)
+		if err != nil {
+			return nil, err
+		}
+		if copyImages {
+			img, err := s.TextureImage(texture)
+			if err != nil {
+				return nil, err
+			}
+			file := exportFile{path: target, source: texture.Image}
+			file.write = func(w io.Writer) error { return png.Encode(w, img) }
+			if err := add(file); err != nil {
+				return nil, err
+			}
+		}
+
+		if exp.texture != nil {
+			file := exportFile{path: trimExt(target) + exp.ext, source: texture.Image}
+			file.write = func(w io.Writer) error { return exp.texture(w, *texture) }
+			if err := add(file); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, frame := range texture.Frames {
+			if exp.check != nil {
+				if err := exp.check(frame); err != nil {
+					return nil, err
+				}
+			}
+			frame := frame
+			name := strings.ReplaceAll(trimExt(frame.FileName), "/", "_") + exp.ext
+			file := exportFile{path: filepath.Join(dir, name), source: frame.FileName}
+			file.write = func(w io.Writer) error { return exp.frame(w, *texture, frame) }
+			if err := add(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// exportPath returns the path of the texture image with the provided name inside the directory. Names leaving the
+// directory, e.g. `../characters.png`, are rejected so an export never writes files outside of it.
+func exportPath(dir, name string) (string, error) {
+	local := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(local) || filepath.VolumeName(local) != "" || local == ".." ||
+		strings.HasPrefix(local, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("texture %s is outside of the directory of the sprite sheet", name)
+	}
+	return filepath.Join(dir, local), nil
+}
+
+// sameDir reports whether both paths refer to the same directory, resolving relative paths and symbolic links.
+func sameDir(a, b string) bool {
+	return resolvePath(a) == resolvePath(b)
+}
+
+// resolvePath returns the absolute path with symbolic links resolved, or as far as it can be resolved.
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// writeFile creates the file at the provided path and passes a buffered writer for it to fn.
+func writeFile(path string, fn func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := fn(w); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// trimExt removes the extension from the file name.
+func trimExt(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// classicFrame defines a frame of the classic hash and array layouts.
+type classicFrame struct {
+	FileName         string        `json:"filename,omitempty"`
+	Frame            jsonRectangle `json:"frame"`
+	Rotated          bool          `json:"rotated"`
+	Trimmed          bool          `json:"trimmed"`
+	SpriteSourceSize jsonRectangle `json:"spriteSourceSize"`
+	SourceSize       jsonDimension `json:"sourceSize"`
+	Duration         int           `json:"duration,omitempty"`
+}
+
+// classicMetaOut defines the `meta` entry written for the classic layouts.
+type classicMetaOut struct {
+	App     string        `json:"app"`
+	Version string        `json:"version"`
+	Image   string        `json:"image"`
+	Format  string        `json:"format"`
+	Size    jsonDimension `json:"size"`
+	Scale   string        `json:"scale"`
+}
+
+func newClassicFrame(frame Frame) classicFrame {
+	return classicFrame{
+		Frame:            frame.Frame,
+		Rotated:          frame.Rotated,
+		Trimmed:          frame.Trimmed,
+		SpriteSourceSize: frame.SpriteSourceSize,
+		SourceSize:       frame.SourceSize,
+	}
+}
+
+func newClassicMeta(app string, texture PackedTexture) classicMetaOut {
+	return classicMetaOut{
+		App:     app,
+		Version: "1.0",
+		Image:   texture.Image,
+		Format:  texture.Format,
+		Size:    texture.Size,
+		Scale:   fmt.Sprint(texture.Scale),
+	}
+}
+
+func exportPhaser(w io.Writer, texture PackedTexture) error {
+	// The frames are written one by one, as encoding a map would sort them by name.
+	if _, err := io.WriteString(w, "{\"frames\": {\n"); err != nil {
+		return err
+	}
+	for i, frame := range texture.Frames {
+		name, _ := json.Marshal(frame.FileName)
+		data, err := json.Marshal(newClassicFrame(frame))
+		if err != nil {
+			return err
+		}
+		separator := ",\n"
+		if i == len(texture.Frames)-1 {
+			separator = "\n"
+		}
+		if _, err := fmt.Fprintf(w, "\t%s: %s%s", name, data, separator); err != nil {
+			return err
+		}
+	}
+	meta, err := json.Marshal(newClassicMeta("vampire-survivors-tools", texture))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "},\n\"meta\": %s\n}\n", meta)
+	return err
+}
+
+func exportAseprite(w io.Writer, texture PackedTexture) error {
+	doc := struct {
+		Frames []classicFrame `json:"frames"`
+		Meta   classicMetaOut `json:"meta"`
+	}{Meta: newClassicMeta("http://www.aseprite.org/", texture)}
+	for _, frame := range texture.Frames {
+		f := newClassicFrame(frame)
+		f.FileName = frame.FileName
+		// Aseprite stores the duration of animation frames in milliseconds.
+		f.Duration = 100
+		doc.Frames = append(doc.Frames, f)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(doc)
+}
+
+// starlingSubTexture defines a SubTexture element of a Starling TextureAtlas.
+type starlingSubTexture struct {
+	Name        string `xml:"name,attr"`
+	X           int    `xml:"x,attr"`
+	Y           int    `xml:"y,attr"`
+	Width       int    `xml:"width,attr"`
+	Height      int    `xml:"height,attr"`
+	FrameX      *int   `xml:"frameX,attr"`
+	FrameY      *int   `xml:"frameY,attr"`
+	FrameWidth  *int   `xml:"frameWidth,attr"`
+	FrameHeight *int   `xml:"frameHeight,attr"`
+	Rotated     bool   `xml:"rotated,attr,omitempty"`
+}
+
+func exportStarling(w io.Writer, texture PackedTexture) error {
+	doc := struct {
+		XMLName     xml.Name             `xml:"TextureAtlas"`
+		ImagePath   string               `xml:"imagePath,attr"`
+		SubTextures []starlingSubTexture `xml:"SubTexture"`
+	}{ImagePath: texture.Image}
+
+	for _, frame := range texture.Frames {
+		// Starling describes the area in the texture, so the width and height of rotated frames are swapped. Trimming
+		// is described by the negative offset of the trimmed area in the original image.
+		region := frame.Region()
+		sub := starlingSubTexture{
+			Name:    frame.FileName,
+			X:       region.Min.X,
+			Y:       region.Min.Y,
+			Width:   region.Dx(),
+			Height:  region.Dy(),
+			Rotated: frame.Rotated,
+		}
+		if frame.Trimmed {
+			x, y := -frame.SpriteSourceSize.X, -frame.SpriteSourceSize.Y
+			width, height := frame.SourceSize.Width, frame.SourceSize.Height
+			sub.FrameX, sub.FrameY, sub.FrameWidth, sub.FrameHeight = &x, &y, &width, &height
+		}
+		doc.SubTextures = append(doc.SubTextures, sub)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// checkGodot returns an error if the frame can't be described by Godot's AtlasTexture.
+func checkGodot(frame Frame) error {
+	if frame.Rotated {
+		return fmt.Errorf("frame %s is rotated, which Godot's AtlasTexture does not support", frame.FileName)
+	}
+	return nil
+}
+
+func exportGodot(w io.Writer, texture PackedTexture, frame Frame) error {
+	if err := checkGodot(frame); err != nil {
+		return err
+	}
+	// The margin restores the trimmed borders: its position is the offset of the trimmed area and its size is the
+	// number of trimmed pixels.
+	margin := jsonRectangle{}
+	if frame.Trimmed {
+		margin = jsonRectangle{
+			jsonDimension: jsonDimension{
+				frame.SourceSize.Width - frame.Frame.Width,
+				frame.SourceSize.Height - frame.Frame.Height,
+			},
+			X: frame.SpriteSourceSize.X,
+			Y: frame.SpriteSourceSize.Y,
+		}
+	}
+	_, err := fmt.Fprintf(w, `[gd_resource type="AtlasTexture" load_steps=2 format=3]
+
+[ext_resource type="Texture2D" path="res://%s" id="1"]
+
+[resource]
+atlas = ExtResource("1")
+region = Rect2(%d, %d, %d, %d)
+margin = Rect2(%d, %d, %d, %d)
+`, texture.Image, frame.Frame.X, frame.Frame.Y, frame.Frame.Width, frame.Frame.Height,
+		margin.X, margin.Y, margin.Width, margin.Height)
+	return err
+}
+
+func exportCSS(w io.Writer, texture PackedTexture) error {
+	if _, err := fmt.Fprintf(w, ".%s {\n\tbackground-image: url(%q);\n\tbackground-repeat: no-repeat;\n}\n",
+		cssClass(trimExt(texture.Image)), texture.Image); err != nil {
+		return err
+	}
+	for _, frame := range texture.Frames {
+		// Trimmed borders are restored as margins, rotated frames are turned back by a transformation around their
+		// top-left corner.
+		region := frame.Region()
+		rule := fmt.Sprintf("\twidth: %dpx;\n\theight: %dpx;\n\tbackground-position: -%dpx -%dpx;\n",
+			region.Dx(), region.Dy(), region.Min.X, region.Min.Y)
+		if frame.Trimmed {
+			rule += fmt.Sprintf("\tmargin: %dpx %dpx %dpx %dpx;\n",
+				frame.SpriteSourceSize.Y,
+				frame.SourceSize.Width-frame.SpriteSourceSize.X-frame.Frame.Width,
+				frame.SourceSize.Height-frame.SpriteSourceSize.Y-frame.Frame.Height,
+				frame.SpriteSourceSize.X)
+		}
+		if frame.Rotated {
+			rule += fmt.Sprintf("\ttransform: translateY(%dpx) rotate(-90deg);\n\ttransform-origin: top left;\n",
+				frame.Frame.Height)
+		}
+		if _, err := fmt.Fprintf(w, ".%s {\n%s}\n", cssClass(trimExt(frame.FileName)), rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cssClass turns the name into a valid CSS class name by replacing invalid characters by dashes.
+func cssClass(name string) string {
+	class := []rune(name)
+	for i, r := range class {
+		valid := r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !valid || i == 0 && r >= '0' && r <= '9' {
+			class[i] = '-'
+		}
+	}
+	return string(class)
+}
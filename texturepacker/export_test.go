@@ -0,0 +1,202 @@
+package texturepacker
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportTestTexture returns a texture with an untrimmed and a trimmed, rotated frame.
+func exportTestTexture() PackedTexture {
+	return PackedTexture{
+		Image:  "sheet.png",
+		Format: "RGBA8888",
+		Size:   jsonDimension{64, 32},
+		Scale:  1,
+		Frames: []Frame{
+			{
+				FileName:         "b/Antonio_01.png",
+				SourceSize:       jsonDimension{10, 12},
+				SpriteSourceSize: jsonRectangle{jsonDimension{10, 12}, 0, 0},
+				Frame:            jsonRectangle{jsonDimension{10, 12}, 1, 2},
+			},
+			{
+				FileName:         "a/1.png",
+				Rotated:          true,
+				Trimmed:          true,
+				SourceSize:       jsonDimension{8, 6},
+				SpriteSourceSize: jsonRectangle{jsonDimension{5, 3}, 2, 1},
+				Frame:            jsonRectangle{jsonDimension{5, 3}, 20, 0},
+			},
+		},
+	}
+}
+
+func Test_exportPhaser(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, exportPhaser(&buf, exportTestTexture()))
+
+	// The export is a sheet in the hash layout, which keeps the order of the frames.
+	var sheet Sheet
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &sheet))
+	expected := exportTestTexture()
+	assert.Equal(t, []PackedTexture{expected}, sheet.Textures)
+	assert.Equal(t, "vampire-survivors-tools", sheet.Metadata.App)
+}
+
+func Test_exportAseprite(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, exportAseprite(&buf, exportTestTexture()))
+
+	var sheet Sheet
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &sheet))
+	assert.Equal(t, []PackedTexture{exportTestTexture()}, sheet.Textures)
+	assert.Contains(t, buf.String(), `"duration": 100`)
+}
+
+func Test_exportStarling(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, exportStarling(&buf, exportTestTexture()))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<TextureAtlas imagePath="sheet.png">
+	<SubTexture name="b/Antonio_01.png" x="1" y="2" width="10" height="12"></SubTexture>
+	<SubTexture name="a/1.png" x="20" y="0" width="3" height="5" frameX="-2" frameY="-1" frameWidth="8" frameHeight="6" rotated="true"></SubTexture>
+</TextureAtlas>
+`, buf.String())
+}
+
+func Test_exportGodot(t *testing.T) {
+	texture := exportTestTexture()
+	var buf bytes.Buffer
+	assert.NoError(t, exportGodot(&buf, texture, texture.Frames[0]))
+	assert.Equal(t, `[gd_resource type="AtlasTexture" load_steps=2 format=3]
+
+[ext_resource type="Texture2D" path="res://sheet.png" id="1"]
+
+[resource]
+atlas = ExtResource("1")
+region = Rect2(1, 2, 10, 12)
+margin = Rect2(0, 0, 0, 0)
+`, buf.String())
+
+	texture.Frames[1].Rotated = false
+	texture.Frames[1].Frame = jsonRectangle{jsonDimension{5, 3}, 20, 0}
+	buf.Reset()
+	assert.NoError(t, exportGodot(&buf, texture, texture.Frames[1]))
+	assert.Contains(t, buf.String(), "margin = Rect2(2, 1, 3, 3)")
+
+	texture.Frames[1].Rotated = true
+	assert.EqualError(t, exportGodot(&buf, texture, texture.Frames[1]),
+		"frame a/1.png is rotated, which Godot's AtlasTexture does not support")
+}
+
+func Test_exportCSS(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, exportCSS(&buf, exportTestTexture()))
+	assert.Equal(t, `.sheet {
+	background-image: url("sheet.png");
+	background-repeat: no-repeat;
+}
+.b-Antonio_01 {
+	width: 10px;
+	height: 12px;
+	background-position: -1px -2px;
+}
+.a-1 {
+	width: 3px;
+	height: 5px;
+	background-position: -20px -0px;
+	margin: 1px 1px 2px 2px;
+	transform: translateY(3px) rotate(-90deg);
+	transform-origin: top left;
+}
+`, buf.String())
+}
+
+func Test_SheetExport(t *testing.T) {
+	path := writeTestSheet(t)
+	sheet, err := Open(path)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	written, err := sheet.Export(FormatGodot, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "sheet.png"),
+		filepath.Join(dir, "Antonio_01.tres"),
+		filepath.Join(dir, "Antonio_02.tres"),
+		filepath.Join(dir, "Imelda_01.tres"),
+	}, written)
+
+	written, err = sheet.Export(FormatStarling, filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(path), "sheet.starling.xml")}, written)
+	data, err := os.ReadFile(written[0])
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "<?xml"))
+
+	// The sheet itself is never overwritten.
+	written, err = sheet.Export(FormatPhaser, filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(path), "sheet.phaser.json")}, written)
+	_, err = Open(path)
+	assert.NoError(t, err)
+
+	_, err = sheet.Export("unknown", dir)
+	assert.EqualError(t, err, "unknown format unknown")
+}
+
+func Test_SheetExportSameDir(t *testing.T) {
+	path := writeTestSheet(t)
+	sheet, err := Open(path)
+	assert.NoError(t, err)
+
+	// The images of the textures are not copied onto themselves if the directory is reached through a symbolic link.
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(filepath.Dir(path), link); err != nil {
+		t.Skipf("could not create symbolic link: %v", err)
+	}
+	written, err := sheet.Export(FormatPhaser, link)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(link, "sheet.phaser.json")}, written)
+}
+
+func Test_exportPath(t *testing.T) {
+	dir := t.TempDir()
+	path, err := exportPath(dir, "textures/sheet.png")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "textures", "sheet.png"), path)
+
+	for _, name := range []string{"../sheet.png", "textures/../../sheet.png", "..", "/tmp/sheet.png"} {
+		_, err := exportPath(dir, name)
+		assert.Error(t, err, name)
+	}
+
+	sheet := &Sheet{Textures: []PackedTexture{exportTestTexture()}, dir: t.TempDir()}
+	sheet.Textures[0].Image = "../sheet.png"
+	_, err = sheet.Export(FormatPhaser, dir)
+	assert.EqualError(t, err, "texture ../sheet.png is outside of the directory of the sprite sheet")
+}
+
+func Test_SheetExportErrors(t *testing.T) {
+	dir := t.TempDir()
+	sheet := &Sheet{Textures: []PackedTexture{exportTestTexture()}, dir: dir}
+	_, err := sheet.Export(FormatGodot, dir)
+	assert.EqualError(t, err, "frame a/1.png is rotated, which Godot's AtlasTexture does not support")
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	sheet.Textures[0].Frames[1] = Frame{FileName: "b_Antonio_01.png"}
+	_, err = sheet.Export(FormatGodot, dir)
+	assert.EqualError(t, err, "b/Antonio_01.png and b_Antonio_01.png would both be exported to "+
+		filepath.Join(dir, "b_Antonio_01.tres"))
+	files, err = os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,44 @@
+package texturepacker
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Region returns the area the Frame occupies in the image of its texture. Texturepacker stores rotated frames turned by
+// 90 degrees clockwise, so their width and height are swapped in the image.
+func (f Frame) Region() image.Rectangle {
+	if f.Rotated {
+		return image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.Height, f.Frame.Y+f.Frame.Width)
+	}
+	return f.Frame.Rect()
+}
+
+// Extract cuts the Frame out of the image of its texture, as it was before packing: rotated frames are turned back
+// upright and trimmed frames are placed at their SpriteSourceSize on a transparent canvas of their SourceSize.
+func (f Frame) Extract(texture image.Image) image.Image {
+	size := f.SourceSize.Point()
+	if size.X == 0 || size.Y == 0 {
+		size = f.Frame.Rect().Size()
+	}
+	canvas := image.NewNRGBA(image.Rectangle{Max: size})
+	offset := image.Pt(f.SpriteSourceSize.X, f.SpriteSourceSize.Y)
+	if !f.Trimmed {
+		offset = image.Point{}
+	}
+
+	region := f.Region().Add(texture.Bounds().Min)
+	if !f.Rotated {
+		dst := image.Rectangle{Min: offset, Max: offset.Add(region.Size())}
+		draw.Draw(canvas, dst, texture, region.Min, draw.Src)
+		return canvas
+	}
+
+	// The pixel at (x, y) of the upright frame is stored at (height-1-y, x) of the rotated region.
+	for y := 0; y < f.Frame.Height; y++ {
+		for x := 0; x < f.Frame.Width; x++ {
+			canvas.Set(offset.X+x, offset.Y+y, texture.At(region.Min.X+f.Frame.Height-1-y, region.Min.Y+x))
+		}
+	}
+	return canvas
+}
@@ -0,0 +1,72 @@
+package texturepacker
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSprite returns a sprite of 3x2 pixels whose pixels all differ.
+func testSprite() *image.NRGBA {
+	sprite := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			sprite.Set(x, y, color.NRGBA{uint8(x * 50), uint8(y * 50), 100, 255})
+		}
+	}
+	return sprite
+}
+
+func Test_FrameExtract(t *testing.T) {
+	sprite := testSprite()
+	texture := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			texture.Set(4+x, 5+y, sprite.At(x, y))
+		}
+	}
+
+	frame := Frame{
+		Frame:      jsonRectangle{jsonDimension{3, 2}, 4, 5},
+		SourceSize: jsonDimension{3, 2},
+	}
+	assert.Equal(t, image.Rect(4, 5, 7, 7), frame.Region())
+	assert.Equal(t, sprite, frame.Extract(texture))
+}
+
+func Test_FrameExtractRotated(t *testing.T) {
+	sprite := testSprite()
+	// Rotate the sprite by 90 degrees clockwise into the texture, as texturepacker does.
+	texture := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			texture.Set(1+(1-y), 2+x, sprite.At(x, y))
+		}
+	}
+
+	frame := Frame{
+		Rotated:    true,
+		Frame:      jsonRectangle{jsonDimension{3, 2}, 1, 2},
+		SourceSize: jsonDimension{3, 2},
+	}
+	assert.Equal(t, image.Rect(1, 2, 3, 5), frame.Region())
+	assert.Equal(t, sprite, frame.Extract(texture))
+}
+
+func Test_FrameExtractTrimmed(t *testing.T) {
+	sprite := testSprite()
+	frame := Frame{
+		Trimmed:          true,
+		Frame:            jsonRectangle{jsonDimension{3, 2}, 0, 0},
+		SpriteSourceSize: jsonRectangle{jsonDimension{3, 2}, 2, 1},
+		SourceSize:       jsonDimension{6, 4},
+	}
+	extracted := frame.Extract(sprite)
+	assert.Equal(t, image.Rect(0, 0, 6, 4), extracted.Bounds())
+	assert.Equal(t, color.NRGBA{}, extracted.At(0, 0))
+	assert.Equal(t, color.NRGBA{}, extracted.At(5, 3))
+	assert.Equal(t, sprite.At(0, 0), extracted.At(2, 1))
+	assert.Equal(t, sprite.At(2, 1), extracted.At(4, 2))
+}
@@ -0,0 +1,374 @@
+package texturepacker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultMaxSize is the maximum width and height of packed textures if PackOptions don't specify them.
+const DefaultMaxSize = 2048
+
+// Sprite is a named image to be packed into a sheet.
+type Sprite struct {
+	// Name holds the file name of the frame, e.g. `Antonio_01.png`.
+	Name  string
+	Image image.Image
+}
+
+// PackOptions configures Pack.
+type PackOptions struct {
+	// Name is the base name of the texture images, e.g. `characters` results in `characters.png`, or in
+	// `characters-0.png`, `characters-1.png` and so on if the sprites don't fit into a single texture.
+	Name string
+	// MaxWidth and MaxHeight limit the size of each texture. Sprites exceeding it are packed into further textures.
+	MaxWidth, MaxHeight int
+	// Padding is the number of transparent pixels between sprites, which prevents neighbours from bleeding into each
+	// other when the game scales them.
+	Padding int
+	// Trim removes transparent borders of the sprites, which are restored from SpriteSourceSize when extracting them.
+	Trim bool
+	// Rotate allows turning sprites by 90 degrees clockwise if they fit better that way.
+	Rotate bool
+}
+
+// Atlas is a packed Sheet along with the images of its textures.
+type Atlas struct {
+	Sheet *Sheet
+	// Images holds the image of each texture of the Sheet, in the same order.
+	Images []*image.NRGBA
+}
+
+// packItem is a sprite being packed.
+type packItem struct {
+	name string
+	img  image.Image
+	// trimmed holds the area of img which is packed.
+	trimmed image.Rectangle
+	// placed holds the position of the sprite in the texture, including the padding.
+	placed  image.Rectangle
+	rotated bool
+}
+
+// Pack packs the sprites into the textures of a new Sheet using the MaxRects algorithm, choosing the free area whose
+// shorter leftover side is the smallest for each sprite, largest sprites first. The images of the Sheet are cached, so
+// its frames can be read right away.
+func Pack(sprites []Sprite, opts PackOptions) (*Atlas, error) {
+	if opts.Name == "" {
+		opts.Name = "atlas"
+	}
+	if opts.MaxWidth == 0 {
+		opts.MaxWidth = DefaultMaxSize
+	}
+	if opts.MaxHeight == 0 {
+		opts.MaxHeight = DefaultMaxSize
+	}
+
+	items := make([]*packItem, len(sprites))
+	names := make(map[string]bool, len(sprites))
+	for i, sprite := range sprites {
+		if names[sprite.Name] {
+			return nil, fmt.Errorf("duplicate sprite %s", sprite.Name)
+		}
+		names[sprite.Name] = true
+
+		trimmed := sprite.Image.Bounds()
+		if opts.Trim {
+			trimmed = opaqueBounds(sprite.Image)
+		}
+		items[i] = &packItem{name: sprite.Name, img: sprite.Image, trimmed: trimmed}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].trimmed.Size(), items[j].trimmed.Size()
+		if maxInt(a.X, a.Y) != maxInt(b.X, b.Y) {
+			return maxInt(a.X, a.Y) > maxInt(b.X, b.Y)
+		}
+		return a.X*a.Y > b.X*b.Y
+	})
+
+	// Padding is added to the right and bottom of every sprite, so the bins are enlarged by it as well to allow sprites
+	// to touch the edges of the texture.
+	var bins []*maxRects
+	var binItems [][]*packItem
+	for _, item := range items {
+		size := item.trimmed.Size().Add(image.Pt(opts.Padding, opts.Padding))
+		placed := false
+		for i, bin := range bins {
+			if item.placed, item.rotated, placed = bin.insert(size, opts.Rotate); placed {
+				binItems[i] = append(binItems[i], item)
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+
+		bin := newMaxRects(opts.MaxWidth+opts.Padding, opts.MaxHeight+opts.Padding)
+		if item.placed, item.rotated, placed = bin.insert(size, opts.Rotate); !placed {
+			return nil, fmt.Errorf("sprite %s of %dx%d pixels exceeds the maximum texture size", item.name,
+				item.trimmed.Dx(), item.trimmed.Dy())
+		}
+		bins = append(bins, bin)
+		binItems = append(binItems, []*packItem{item})
+	}
+
+	atlas := &Atlas{Sheet: new(Sheet)}
+	atlas.Sheet.Metadata.App = "vampire-survivors-tools"
+	atlas.Sheet.Metadata.Version = "1.0"
+	atlas.Sheet.cache = &textureCache{images: make(map[string]image.Image)}
+	for i, packed := range binItems {
+		name := textureName(opts.Name, i, len(binItems))
+		texture, img := renderTexture(name, packed, opts.Padding)
+		atlas.Sheet.Textures = append(atlas.Sheet.Textures, texture)
+		atlas.Sheet.cache.images[name] = img
+		atlas.Images = append(atlas.Images, img)
+	}
+	return atlas, nil
+}
+
+// textureName returns the file name of the i-th of count textures named after the provided base name.
+func textureName(base string, i, count int) string {
+	if count > 1 {
+		return fmt.Sprintf("%s-%d.png", base, i)
+	}
+	return base + ".png"
+}
+
+// renderTexture draws the packed items into the image of a texture, which is cropped to the area used, and describes
+// them as its frames.
+func renderTexture(name string, items []*packItem, padding int) (PackedTexture, *image.NRGBA) {
+	var used image.Rectangle
+	for _, item := range items {
+		used = used.Union(item.placed)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, maxInt(used.Max.X-padding, 1), maxInt(used.Max.Y-padding, 1)))
+
+	texture := PackedTexture{Image: name, Format: "RGBA8888", Size: jsonDimension{img.Rect.Dx(), img.Rect.Dy()},
+		Scale: 1}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].name < items[j].name
+	})
+	for _, item := range items {
+		src, bounds := item.trimmed, item.img.Bounds()
+		frame := Frame{
+			FileName:   item.name,
+			Rotated:    item.rotated,
+			Trimmed:    src != bounds,
+			SourceSize: jsonDimension{bounds.Dx(), bounds.Dy()},
+			SpriteSourceSize: jsonRectangle{
+				jsonDimension: jsonDimension{src.Dx(), src.Dy()},
+				X:             src.Min.X - bounds.Min.X,
+				Y:             src.Min.Y - bounds.Min.Y,
+			},
+			Frame: jsonRectangle{jsonDimension{src.Dx(), src.Dy()}, item.placed.Min.X, item.placed.Min.Y},
+		}
+
+		frame.draw(img, item.img, src.Min)
+		texture.Frames = append(texture.Frames, frame)
+	}
+	return texture, img
+}
+
+// draw draws the area of the sprite starting at the provided point into the region of the Frame in the image of its
+// texture, rotating it if necessary. This is the reverse of Frame.Extract.
+func (f Frame) draw(texture draw.Image, sprite image.Image, sp image.Point) {
+	if !f.Rotated {
+		draw.Draw(texture, f.Region(), sprite, sp, draw.Src)
+		return
+	}
+	// The pixel at (x, y) of the sprite is stored at (height-1-y, x) of the rotated region.
+	for y := 0; y < f.Frame.Height; y++ {
+		for x := 0; x < f.Frame.Width; x++ {
+			texture.Set(f.Frame.X+f.Frame.Height-1-y, f.Frame.Y+x, sprite.At(sp.X+x, sp.Y+y))
+		}
+	}
+}
+
+// visibleBounds returns the smallest area of the image containing all of its pixels which are not fully transparent.
+// It is empty if the image is fully transparent.
+func visibleBounds(img image.Image) image.Rectangle {
+	bounds := img.Bounds()
+	var visible image.Rectangle
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				visible = visible.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return visible
+}
+
+// opaqueBounds returns the visibleBounds of the image, used for trimming. Fully transparent images are trimmed to their
+// top-left pixel.
+func opaqueBounds(img image.Image) image.Rectangle {
+	if visible := visibleBounds(img); !visible.Empty() {
+		return visible
+	}
+	bounds := img.Bounds()
+	return image.Rectangle{Min: bounds.Min, Max: bounds.Min.Add(image.Pt(1, 1))}.Intersect(bounds)
+}
+
+// maxRects keeps track of the free areas of a texture being packed.
+type maxRects struct {
+	free []image.Rectangle
+}
+
+func newMaxRects(width, height int) *maxRects {
+	return &maxRects{free: []image.Rectangle{image.Rect(0, 0, width, height)}}
+}
+
+// insert places an area of the provided size, returning its position and whether it has been rotated. It reports false
+// if the area does not fit.
+func (m *maxRects) insert(size image.Point, rotate bool) (image.Rectangle, bool, bool) {
+	best, bestShort, bestLong := image.Rectangle{}, -1, -1
+	rotated := false
+	consider := func(free image.Rectangle, w, h int, rot bool) {
+		if w > free.Dx() || h > free.Dy() {
+			return
+		}
+		leftX, leftY := free.Dx()-w, free.Dy()-h
+		short, long := minInt(leftX, leftY), maxInt(leftX, leftY)
+		if bestShort < 0 || short < bestShort || short == bestShort && long < bestLong {
+			best = image.Rect(free.Min.X, free.Min.Y, free.Min.X+w, free.Min.Y+h)
+			bestShort, bestLong, rotated = short, long, rot
+		}
+	}
+	for _, free := range m.free {
+		consider(free, size.X, size.Y, false)
+		if rotate && size.X != size.Y {
+			consider(free, size.Y, size.X, true)
+		}
+	}
+	if bestShort < 0 {
+		return image.Rectangle{}, false, false
+	}
+	m.place(best)
+	return best, rotated, true
+}
+
+// place removes the used area from the free areas, splitting the ones it overlaps into the maximal remaining areas.
+func (m *maxRects) place(used image.Rectangle) {
+	var free []image.Rectangle
+	for _, r := range m.free {
+		if !r.Overlaps(used) {
+			free = append(free, r)
+			continue
+		}
+		if used.Min.X > r.Min.X {
+			free = append(free, image.Rect(r.Min.X, r.Min.Y, used.Min.X, r.Max.Y))
+		}
+		if used.Max.X < r.Max.X {
+			free = append(free, image.Rect(used.Max.X, r.Min.Y, r.Max.X, r.Max.Y))
+		}
+		if used.Min.Y > r.Min.Y {
+			free = append(free, image.Rect(r.Min.X, r.Min.Y, r.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < r.Max.Y {
+			free = append(free, image.Rect(r.Min.X, used.Max.Y, r.Max.X, r.Max.Y))
+		}
+	}
+
+	// Drop areas contained in others.
+	m.free = m.free[:0]
+	for i, r := range free {
+		contained := false
+		for j, other := range free {
+			if i != j && r.In(other) && (r != other || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			m.free = append(m.free, r)
+		}
+	}
+}
+
+// WriteFiles writes the Sheet as JSON to the provided path and the images of its textures as PNG files next to it.
+func (a *Atlas) WriteFiles(path string) error {
+	if len(a.Images) != len(a.Sheet.Textures) {
+		return errors.New("atlas must contain an image for every texture")
+	}
+	dir := filepath.Dir(path)
+	for i, texture := range a.Sheet.Textures {
+		if err := writePNG(filepath.Join(dir, texture.Image), a.Images[i]); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(a.Sheet, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	a.Sheet.dir = dir
+	return nil
+}
+
+// textureNameExp matches the names of the textures written by Pack, capturing their base name.
+var textureNameExp = regexp.MustCompile(`^(.+?)(-\d+)?\.png$`)
+
+// StaleTextures returns the paths of the PNG files next to the provided path which are named like the textures of the
+// Atlas, e.g. `characters.png` or `characters-3.png`, but are not referenced by its Sheet. These files are left behind
+// if a sheet is written again using fewer textures than before.
+func (a *Atlas) StaleTextures(path string) ([]string, error) {
+	dir := filepath.Dir(path)
+	referenced := make(map[string]bool, len(a.Sheet.Textures))
+	bases := make(map[string]bool)
+	for _, texture := range a.Sheet.Textures {
+		referenced[texture.Image] = true
+		if match := textureNameExp.FindStringSubmatch(texture.Image); match != nil {
+			bases[match[1]] = true
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, file := range files {
+		match := textureNameExp.FindStringSubmatch(file.Name())
+		if file.IsDir() || referenced[file.Name()] || match == nil || !bases[match[1]] {
+			continue
+		}
+		stale = append(stale, filepath.Join(dir, file.Name()))
+	}
+	return stale, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
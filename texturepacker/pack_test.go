@@ -0,0 +1,130 @@
+package texturepacker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSprites returns sprites of various sizes with a transparent border of one pixel.
+func testSprites() []Sprite {
+	var sprites []Sprite
+	for i, size := range []image.Point{{10, 4}, {3, 3}, {6, 12}, {5, 5}, {2, 9}, {7, 1}} {
+		img := image.NewNRGBA(image.Rect(0, 0, size.X+2, size.Y+2))
+		for y := 1; y <= size.Y; y++ {
+			for x := 1; x <= size.X; x++ {
+				img.Set(x, y, color.NRGBA{uint8(i * 30), uint8(x * 10), uint8(y * 10), 255})
+			}
+		}
+		sprites = append(sprites, Sprite{fmt.Sprintf("sprite_%02d.png", i), img})
+	}
+	return sprites
+}
+
+// assertSameImage asserts that both images have the same bounds and colors.
+func assertSameImage(t *testing.T, expected, actual image.Image, msg string) {
+	assert.Equal(t, expected.Bounds(), actual.Bounds(), msg)
+	for y := expected.Bounds().Min.Y; y < expected.Bounds().Max.Y; y++ {
+		for x := expected.Bounds().Min.X; x < expected.Bounds().Max.X; x++ {
+			er, eg, eb, ea := expected.At(x, y).RGBA()
+			ar, ag, ab, aa := actual.At(x, y).RGBA()
+			if ea == 0 && aa == 0 {
+				continue
+			}
+			if !assert.Equal(t, []uint32{er, eg, eb, ea}, []uint32{ar, ag, ab, aa}, "%s at %d,%d", msg, x, y) {
+				return
+			}
+		}
+	}
+}
+
+func Test_Pack(t *testing.T) {
+	for _, opts := range []PackOptions{
+		{},
+		{Trim: true},
+		{Trim: true, Rotate: true, Padding: 2},
+		{Rotate: true, MaxWidth: 16, MaxHeight: 16},
+	} {
+		msg := fmt.Sprintf("%+v", opts)
+		sprites := testSprites()
+		atlas, err := Pack(sprites, opts)
+		assert.NoError(t, err, msg)
+
+		regions := make(map[string][]image.Rectangle)
+		for _, frame := range atlas.Sheet.Frames() {
+			region := frame.Region()
+			assert.True(t, region.In(image.Rect(0, 0, frame.Texture.Size.Width, frame.Texture.Size.Height)), msg)
+			for _, other := range regions[frame.Texture.Image] {
+				assert.False(t, region.Overlaps(other.Inset(-opts.Padding)), msg)
+			}
+			regions[frame.Texture.Image] = append(regions[frame.Texture.Image], region)
+			assert.Equal(t, opts.Trim, frame.Trimmed, msg)
+		}
+
+		for _, sprite := range sprites {
+			img, err := atlas.Sheet.Frame(sprite.Name)
+			assert.NoError(t, err, msg)
+			assertSameImage(t, sprite.Image, img, msg+" "+sprite.Name)
+		}
+	}
+}
+
+func Test_PackMultipack(t *testing.T) {
+	atlas, err := Pack(testSprites(), PackOptions{Name: "mod", MaxWidth: 14, MaxHeight: 14})
+	assert.NoError(t, err)
+	assert.Greater(t, len(atlas.Sheet.Textures), 1)
+	assert.Len(t, atlas.Images, len(atlas.Sheet.Textures))
+	assert.Equal(t, "mod-0.png", atlas.Sheet.Textures[0].Image)
+	assert.Len(t, atlas.Sheet.Frames(), 6)
+}
+
+func Test_PackErrors(t *testing.T) {
+	sprites := testSprites()
+	_, err := Pack(sprites, PackOptions{MaxWidth: 8, MaxHeight: 8})
+	assert.EqualError(t, err, "sprite sprite_02.png of 8x14 pixels exceeds the maximum texture size")
+
+	_, err = Pack(append(sprites, sprites[0]), PackOptions{})
+	assert.EqualError(t, err, "duplicate sprite sprite_00.png")
+}
+
+func Test_AtlasWriteFiles(t *testing.T) {
+	sprites := testSprites()
+	atlas, err := Pack(sprites, PackOptions{Name: "mod", Trim: true, Rotate: true})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "mod.json")
+	assert.NoError(t, atlas.WriteFiles(path))
+
+	sheet, err := Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, atlas.Sheet.Textures, sheet.Textures)
+	for _, sprite := range sprites {
+		img, err := sheet.Frame(sprite.Name)
+		assert.NoError(t, err)
+		assertSameImage(t, sprite.Image, img, sprite.Name)
+	}
+}
+
+func Test_AtlasStaleTextures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mod.json")
+	multipack, err := Pack(testSprites(), PackOptions{Name: "mod", MaxWidth: 14, MaxHeight: 14})
+	assert.NoError(t, err)
+	assert.NoError(t, multipack.WriteFiles(path))
+	assert.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "modded.png"), nil, 0644))
+
+	atlas, err := Pack(testSprites(), PackOptions{Name: "mod"})
+	assert.NoError(t, err)
+	assert.NoError(t, atlas.WriteFiles(path))
+
+	stale, err := atlas.StaleTextures(path)
+	assert.NoError(t, err)
+	assert.Len(t, stale, len(multipack.Sheet.Textures))
+	for i, texture := range multipack.Sheet.Textures {
+		assert.Equal(t, filepath.Join(filepath.Dir(path), texture.Image), stale[i])
+	}
+}
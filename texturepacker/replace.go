@@ -0,0 +1,135 @@
+package texturepacker
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"path"
+	"strings"
+)
+
+// ReplaceReport describes how Replace applied the replacements.
+type ReplaceReport struct {
+	// InPlace lists the frames which were written into their existing area of the texture.
+	InPlace []string
+	// Repacked lists the replaced frames if any of them did not fit into its existing area, in which case the whole
+	// sheet has been repacked.
+	Repacked []string
+}
+
+// Replace swaps the images of frames of the sheet with the provided sprites, which are named by the file names of the
+// frames. A sprite is written into the area of its frame if it has the same size as the original image and, for
+// trimmed frames, doesn't cover pixels outside the trimmed area. Otherwise, all frames are repacked using the provided
+// options, keeping the metadata of the sheet. The sheet itself is not modified. If the options specify a name, the
+// textures are named after it either way, otherwise they keep their names when written in place.
+func Replace(sheet *Sheet, sprites []Sprite, opts PackOptions) (*Atlas, *ReplaceReport, error) {
+	replacements := make(map[string]image.Image, len(sprites))
+	for _, sprite := range sprites {
+		if _, err := sheet.Lookup(sprite.Name); err != nil {
+			return nil, nil, err
+		}
+		replacements[sprite.Name] = sprite.Image
+	}
+
+	report := new(ReplaceReport)
+	for _, frame := range sheet.Frames() {
+		img, ok := replacements[frame.FileName]
+		if !ok {
+			continue
+		}
+		if frame.fits(img) {
+			report.InPlace = append(report.InPlace, frame.FileName)
+		} else {
+			report.Repacked = append(report.Repacked, frame.FileName)
+		}
+	}
+
+	if len(report.Repacked) > 0 {
+		atlas, err := repack(sheet, replacements, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		report.Repacked = append(report.Repacked, report.InPlace...)
+		report.InPlace = nil
+		return atlas, report, nil
+	}
+
+	atlas := &Atlas{Sheet: &Sheet{Metadata: sheet.Metadata, dir: sheet.dir}}
+	atlas.Sheet.cache = &textureCache{images: make(map[string]image.Image)}
+	for i, texture := range sheet.Textures {
+		src, err := sheet.TextureImage(&texture)
+		if err != nil {
+			return nil, nil, err
+		}
+		img := image.NewNRGBA(image.Rectangle{Max: src.Bounds().Size()})
+		draw.Draw(img, img.Rect, src, src.Bounds().Min, draw.Src)
+
+		for _, frame := range texture.Frames {
+			replacement, ok := replacements[frame.FileName]
+			if !ok {
+				continue
+			}
+			// Clear the area first, as the replacement may be transparent where the original was not.
+			draw.Draw(img, frame.Region(), image.Transparent, image.Point{}, draw.Src)
+			sp := replacement.Bounds().Min
+			if frame.Trimmed {
+				sp = sp.Add(image.Pt(frame.SpriteSourceSize.X, frame.SpriteSourceSize.Y))
+			}
+			frame.draw(img, replacement, sp)
+		}
+
+		texture.Frames = append([]Frame{}, texture.Frames...)
+		if opts.Name != "" {
+			texture.Image = textureName(opts.Name, i, len(sheet.Textures))
+		}
+		atlas.Sheet.Textures = append(atlas.Sheet.Textures, texture)
+		atlas.Sheet.cache.images[texture.Image] = img
+		atlas.Images = append(atlas.Images, img)
+	}
+	return atlas, report, nil
+}
+
+// fits reports whether the image can replace the one of the Frame without changing its area in the texture.
+func (f Frame) fits(img image.Image) bool {
+	size := f.SourceSize.Point()
+	if size.X == 0 || size.Y == 0 {
+		size = f.Frame.Rect().Size()
+	}
+	if img.Bounds().Size() != size {
+		return false
+	}
+	if !f.Trimmed {
+		return true
+	}
+	// Fully transparent images have empty bounds, which are in any area.
+	return visibleBounds(img).In(f.SpriteSourceSize.Rect().Add(img.Bounds().Min))
+}
+
+// repack packs all frames of the sheet into a new Atlas, using the replacements in place of the original images. The
+// textures are named after the first texture of the sheet unless the options specify a name.
+func repack(sheet *Sheet, replacements map[string]image.Image, opts PackOptions) (*Atlas, error) {
+	if opts.Name == "" && len(sheet.Textures) > 0 {
+		name := sheet.Textures[0].Image
+		opts.Name = strings.TrimSuffix(name, path.Ext(name))
+	}
+
+	var sprites []Sprite
+	for _, frame := range sheet.Frames() {
+		img, ok := replacements[frame.FileName]
+		if !ok {
+			var err error
+			if img, err = frame.Image(); err != nil {
+				return nil, fmt.Errorf("could not extract %s: %w", frame.FileName, err)
+			}
+		}
+		sprites = append(sprites, Sprite{frame.FileName, img})
+	}
+
+	atlas, err := Pack(sprites, opts)
+	if err != nil {
+		return nil, err
+	}
+	atlas.Sheet.Metadata = sheet.Metadata
+	atlas.Sheet.dir = sheet.dir
+	return atlas, nil
+}
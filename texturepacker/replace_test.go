@@ -0,0 +1,122 @@
+package texturepacker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recolor returns a copy of the image whose visible pixels are white.
+func recolor(img image.Image) *image.NRGBA {
+	recolored := image.NewNRGBA(img.Bounds())
+	draw.Draw(recolored, recolored.Rect, img, img.Bounds().Min, draw.Src)
+	for y := recolored.Rect.Min.Y; y < recolored.Rect.Max.Y; y++ {
+		for x := recolored.Rect.Min.X; x < recolored.Rect.Max.X; x++ {
+			if recolored.NRGBAAt(x, y).A != 0 {
+				recolored.Set(x, y, color.White)
+			}
+		}
+	}
+	return recolored
+}
+
+func Test_ReplaceInPlace(t *testing.T) {
+	sprites := testSprites()
+	atlas, err := Pack(sprites, PackOptions{Trim: true, Rotate: true})
+	assert.NoError(t, err)
+
+	replacements := []Sprite{
+		{sprites[2].Name, recolor(sprites[2].Image)},
+		{sprites[4].Name, image.NewNRGBA(sprites[4].Image.Bounds())},
+	}
+	replaced, report, err := Replace(atlas.Sheet, replacements, PackOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sprites[2].Name, sprites[4].Name}, report.InPlace)
+	assert.Empty(t, report.Repacked)
+	assert.Equal(t, atlas.Sheet.Textures, replaced.Sheet.Textures)
+
+	for _, sprite := range sprites {
+		expected := sprite.Image
+		for _, replacement := range replacements {
+			if replacement.Name == sprite.Name {
+				expected = replacement.Image
+			}
+		}
+		img, err := replaced.Sheet.Frame(sprite.Name)
+		assert.NoError(t, err)
+		assertSameImage(t, expected, img, sprite.Name)
+	}
+
+	// The original sheet is untouched.
+	img, err := atlas.Sheet.Frame(sprites[2].Name)
+	assert.NoError(t, err)
+	assertSameImage(t, sprites[2].Image, img, sprites[2].Name)
+}
+
+func Test_ReplaceInPlaceName(t *testing.T) {
+	sprites := testSprites()
+	atlas, err := Pack(sprites, PackOptions{Name: "characters", MaxWidth: 16, MaxHeight: 16})
+	assert.NoError(t, err)
+	assert.Greater(t, len(atlas.Sheet.Textures), 1)
+
+	replaced, report, err := Replace(atlas.Sheet, []Sprite{{sprites[0].Name, recolor(sprites[0].Image)}},
+		PackOptions{Name: "mod"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sprites[0].Name}, report.InPlace)
+	for i, texture := range replaced.Sheet.Textures {
+		assert.Equal(t, fmt.Sprintf("mod-%d.png", i), texture.Image)
+		assert.Equal(t, atlas.Sheet.Textures[i].Frames, texture.Frames)
+	}
+
+	img, err := replaced.Sheet.Frame(sprites[0].Name)
+	assert.NoError(t, err)
+	assertSameImage(t, recolor(sprites[0].Image), img, sprites[0].Name)
+}
+
+func Test_ReplaceRepack(t *testing.T) {
+	sprites := testSprites()
+	atlas, err := Pack(sprites, PackOptions{Name: "mod", Trim: true})
+	assert.NoError(t, err)
+	atlas.Sheet.Metadata.App = "TexturePacker"
+
+	// The first replacement is larger than the original, the second covers pixels outside of the trimmed area.
+	larger := image.NewNRGBA(image.Rect(0, 0, 30, 30))
+	larger.Set(15, 15, color.White)
+	outside := recolor(sprites[1].Image)
+	outside.Set(0, 0, color.White)
+	replacements := []Sprite{
+		{sprites[0].Name, larger},
+		{sprites[1].Name, outside},
+		{sprites[3].Name, recolor(sprites[3].Image)},
+	}
+
+	replaced, report, err := Replace(atlas.Sheet, replacements, PackOptions{Trim: true})
+	assert.NoError(t, err)
+	assert.Empty(t, report.InPlace)
+	assert.ElementsMatch(t, []string{sprites[0].Name, sprites[1].Name, sprites[3].Name}, report.Repacked)
+	assert.Equal(t, "TexturePacker", replaced.Sheet.Metadata.App)
+	assert.Equal(t, "mod.png", replaced.Sheet.Textures[0].Image)
+
+	for _, sprite := range sprites {
+		expected := sprite.Image
+		for _, replacement := range replacements {
+			if replacement.Name == sprite.Name {
+				expected = replacement.Image
+			}
+		}
+		img, err := replaced.Sheet.Frame(sprite.Name)
+		assert.NoError(t, err)
+		assertSameImage(t, expected, img, sprite.Name)
+	}
+}
+
+func Test_ReplaceUnknownFrame(t *testing.T) {
+	atlas, err := Pack(testSprites(), PackOptions{})
+	assert.NoError(t, err)
+	_, _, err = Replace(atlas.Sheet, []Sprite{{"unknown.png", image.NewNRGBA(image.Rect(0, 0, 1, 1))}}, PackOptions{})
+	assert.ErrorIs(t, err, ErrFrameNotFound)
+}
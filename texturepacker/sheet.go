@@ -0,0 +1,117 @@
+package texturepacker
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// ErrFrameNotFound is returned if a sheet does not contain a requested frame.
+var ErrFrameNotFound = errors.New("frame not found")
+
+// cacheMu guards the allocation of the caches of all sheets.
+var cacheMu sync.Mutex
+
+// textureCache holds the decoded images of the textures of a Sheet, mapped by their file names.
+type textureCache struct {
+	mu     sync.Mutex
+	images map[string]image.Image
+}
+
+// SheetFrame is a Frame along with the PackedTexture containing it.
+type SheetFrame struct {
+	Frame
+	Texture *PackedTexture
+
+	sheet *Sheet
+}
+
+// Image returns the image of the frame, extracted from the image of its texture by Frame.Extract.
+func (f SheetFrame) Image() (image.Image, error) {
+	texture, err := f.sheet.TextureImage(f.Texture)
+	if err != nil {
+		return nil, err
+	}
+	return f.Extract(texture), nil
+}
+
+// Frames returns the frames of all textures of the Sheet, in the order they are listed.
+func (s *Sheet) Frames() []SheetFrame {
+	var frames []SheetFrame
+	for i := range s.Textures {
+		texture := &s.Textures[i]
+		for _, frame := range texture.Frames {
+			frames = append(frames, SheetFrame{frame, texture, s})
+		}
+	}
+	return frames
+}
+
+// Lookup returns the frame with the provided file name.
+func (s *Sheet) Lookup(name string) (SheetFrame, error) {
+	for _, frame := range s.Frames() {
+		if frame.FileName == name {
+			return frame, nil
+		}
+	}
+	return SheetFrame{}, fmt.Errorf("%w: %s", ErrFrameNotFound, name)
+}
+
+// Frame returns the image of the frame with the provided file name.
+func (s *Sheet) Frame(name string) (image.Image, error) {
+	frame, err := s.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return frame.Image()
+}
+
+// Glob returns the frames whose file names match the provided pattern, using the syntax of path.Match, e.g.
+// `Antonio_*.png`.
+func (s *Sheet) Glob(pattern string) ([]SheetFrame, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	var matches []SheetFrame
+	for _, frame := range s.Frames() {
+		if ok, _ := path.Match(pattern, frame.FileName); ok {
+			matches = append(matches, frame)
+		}
+	}
+	return matches, nil
+}
+
+// TextureImage returns the decoded image of the texture. Images are located relative to the sheet, if it was read by
+// Open, or to the working directory otherwise. They are decoded on first use and cached afterwards.
+func (s *Sheet) TextureImage(texture *PackedTexture) (image.Image, error) {
+	cacheMu.Lock()
+	if s.cache == nil {
+		s.cache = &textureCache{images: make(map[string]image.Image)}
+	}
+	cache := s.cache
+	cacheMu.Unlock()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if img, ok := cache.images[texture.Image]; ok {
+		return img, nil
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, texture.Image))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", texture.Image, err)
+	}
+	cache.images[texture.Image] = img
+	return img, nil
+}
@@ -0,0 +1,79 @@
+package texturepacker
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestSheet writes a sheet in the hash layout along with its texture into a temporary directory and returns the
+// path of the sheet.
+func writeTestSheet(t *testing.T) string {
+	dir := t.TempDir()
+	texture := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	texture.Set(4, 5, testSprite().At(0, 0))
+
+	file, err := os.Create(filepath.Join(dir, "sheet.png"))
+	assert.NoError(t, err)
+	assert.NoError(t, png.Encode(file, texture))
+	assert.NoError(t, file.Close())
+
+	sheet := `{
+		"frames": {
+			"Antonio_01.png": {"frame": {"x": 4, "y": 5, "w": 3, "h": 2}, "sourceSize": {"w": 3, "h": 2}},
+			"Antonio_02.png": {"frame": {"x": 0, "y": 0, "w": 2, "h": 2}, "sourceSize": {"w": 2, "h": 2}},
+			"Imelda_01.png": {"frame": {"x": 0, "y": 2, "w": 2, "h": 2}, "sourceSize": {"w": 2, "h": 2}}
+		},
+		"meta": {"image": "sheet.png", "size": {"w": 8, "h": 8}, "scale": "1"}
+	}`
+	path := filepath.Join(dir, "sheet.json")
+	assert.NoError(t, os.WriteFile(path, []byte(sheet), 0644))
+	return path
+}
+
+func Test_SheetFrame(t *testing.T) {
+	path := writeTestSheet(t)
+	sheet, err := Open(path)
+	assert.NoError(t, err)
+
+	img, err := sheet.Frame("Antonio_01.png")
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 3, 2), img.Bounds())
+	r, g, b, a := img.At(0, 0).RGBA()
+	er, eg, eb, ea := testSprite().At(0, 0).RGBA()
+	assert.Equal(t, []uint32{er, eg, eb, ea}, []uint32{r, g, b, a})
+
+	_, err = sheet.Frame("Missing.png")
+	assert.ErrorIs(t, err, ErrFrameNotFound)
+
+	// The texture is cached, so it is not read again.
+	assert.NoError(t, os.Remove(filepath.Join(filepath.Dir(path), "sheet.png")))
+	_, err = sheet.Frame("Imelda_01.png")
+	assert.NoError(t, err)
+}
+
+func Test_SheetFramesAndGlob(t *testing.T) {
+	sheet, err := Open(writeTestSheet(t))
+	assert.NoError(t, err)
+
+	frames := sheet.Frames()
+	assert.Len(t, frames, 3)
+	assert.Equal(t, "Antonio_01.png", frames[0].FileName)
+	assert.Equal(t, "sheet.png", frames[0].Texture.Image)
+
+	matches, err := sheet.Glob("Antonio_*.png")
+	assert.NoError(t, err)
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "Antonio_02.png", matches[1].FileName)
+
+	img, err := matches[1].Image()
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 2, 2), img.Bounds())
+
+	_, err = sheet.Glob("[")
+	assert.Error(t, err)
+}
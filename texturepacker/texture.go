@@ -1,10 +1,15 @@
 package texturepacker
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
 )
 
 // Open opens and parses the texturepacker-packed sprite sheet located at the specified path.
@@ -14,7 +19,13 @@ func Open(path string) (*Sheet, error) {
 		return nil, err
 	}
 	defer file.Close()
-	return unmarshalPackedTextures(file)
+
+	sheet, err := unmarshalPackedTextures(file)
+	if err != nil {
+		return nil, err
+	}
+	sheet.dir = filepath.Dir(path)
+	return sheet, nil
 }
 
 // unmarshalPackedTextures parses a texturepacker-packed sprite sheet from the provided reader.
@@ -28,6 +39,10 @@ func unmarshalPackedTextures(r io.Reader) (sheet *Sheet, err error) {
 }
 
 // Sheet defines a sprite sheet of the Vampire Survivors game, packed using texturepacker.
+//
+// Texturepacker writes three JSON layouts: the multipack layout listing several textures in `textures`, and the classic
+// hash and array layouts, which list the frames of a single texture in `frames`, keyed by their file name or as array,
+// and describe the texture in `meta`. All of them are normalized into Textures.
 type Sheet struct {
 	Textures []PackedTexture `json:"textures"`
 	Metadata struct {
@@ -35,6 +50,11 @@ type Sheet struct {
 		Version     string `json:"version"`
 		SmartUpdate string `json:"smartupdate"`
 	} `json:"meta"`
+
+	// dir holds the directory of the sheet, which the images of the textures are relative to.
+	dir string
+	// cache holds the decoded images of the textures. It is allocated on first use.
+	cache *textureCache
 }
 
 // PackedTexture defines a texture packed by texturepacker.
@@ -42,10 +62,110 @@ type PackedTexture struct {
 	Image  string        `json:"image"`
 	Format string        `json:"format"`
 	Size   jsonDimension `json:"size"`
-	Scale  int           `json:"scale"`
+	Scale  float64       `json:"scale"`
 	Frames []Frame       `json:"frames"`
 }
 
+// classicMeta defines the `meta` entry of the hash and array layouts, which describes the single texture of the sheet.
+type classicMeta struct {
+	Image  string          `json:"image"`
+	Format string          `json:"format"`
+	Size   jsonDimension   `json:"size"`
+	Scale  json.RawMessage `json:"scale"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, detecting the layout of the sheet.
+func (s *Sheet) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Textures []PackedTexture `json:"textures"`
+		Frames   json.RawMessage `json:"frames"`
+		Meta     json.RawMessage `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = Sheet{}
+	if len(raw.Meta) > 0 {
+		if err := json.Unmarshal(raw.Meta, &s.Metadata); err != nil {
+			return err
+		}
+	}
+
+	switch frames := bytes.TrimSpace(raw.Frames); {
+	case raw.Textures != nil:
+		s.Textures = raw.Textures
+		return nil
+	case len(frames) == 0:
+		return errors.New("sprite sheet contains neither textures nor frames")
+	default:
+		var meta classicMeta
+		if len(raw.Meta) > 0 {
+			if err := json.Unmarshal(raw.Meta, &meta); err != nil {
+				return err
+			}
+		}
+		scale, err := parseScale(meta.Scale)
+		if err != nil {
+			return err
+		}
+		texture := PackedTexture{Image: meta.Image, Format: meta.Format, Size: meta.Size, Scale: scale}
+
+		if frames[0] == '[' {
+			err = json.Unmarshal(frames, &texture.Frames)
+		} else {
+			texture.Frames, err = unmarshalFrameHash(frames)
+		}
+		if err != nil {
+			return err
+		}
+		s.Textures = []PackedTexture{texture}
+		return nil
+	}
+}
+
+// unmarshalFrameHash parses the frames of the hash layout, which are keyed by their file name. Unlike decoding into a
+// map, this keeps the order of the frames.
+func unmarshalFrameHash(data []byte) ([]Frame, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if t, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, fmt.Errorf("expected frames to be an object or an array but got %v", t)
+	}
+
+	var frames []Frame
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame Frame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.FileName = t.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// parseScale parses the scale of a texture, which the classic layouts store as string.
+func parseScale(data json.RawMessage) (float64, error) {
+	if len(data) == 0 {
+		return 1, nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		text = string(data)
+	}
+	scale, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scale %s", data)
+	}
+	return scale, nil
+}
+
 // Frame defines a frame entry of a PackedTexture.
 type Frame struct {
 	FileName         string        `json:"filename"`
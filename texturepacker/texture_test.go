@@ -56,3 +56,66 @@ func createTestSheet() (Sheet, []byte) {
 	data, _ := json.Marshal(&sheet)
 	return sheet, data
 }
+
+func Test_unmarshalPackedTexturesHash(t *testing.T) {
+	data := `{
+		"frames": {
+			"b.png": {"frame": {"x": 1, "y": 2, "w": 3, "h": 4}, "rotated": true, "trimmed": false,
+				"spriteSourceSize": {"x": 0, "y": 0, "w": 4, "h": 3}, "sourceSize": {"w": 4, "h": 3}},
+			"a.png": {"frame": {"x": 5, "y": 6, "w": 7, "h": 8}, "rotated": false, "trimmed": true,
+				"spriteSourceSize": {"x": 1, "y": 1, "w": 7, "h": 8}, "sourceSize": {"w": 9, "h": 10}}
+		},
+		"meta": {"app": "dummyApp", "version": "1.0", "image": "sheet.png", "format": "RGBA8888",
+			"size": {"w": 64, "h": 32}, "scale": "0.5", "smartupdate": "dummyUpdate"}
+	}`
+	sheet, err := unmarshalPackedTextures(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "dummyApp", sheet.Metadata.App)
+	assert.Len(t, sheet.Textures, 1)
+
+	texture := sheet.Textures[0]
+	assert.Equal(t, "sheet.png", texture.Image)
+	assert.Equal(t, "RGBA8888", texture.Format)
+	assert.Equal(t, jsonDimension{64, 32}, texture.Size)
+	assert.Equal(t, 0.5, texture.Scale)
+	assert.Len(t, texture.Frames, 2)
+	assert.Equal(t, "b.png", texture.Frames[0].FileName)
+	assert.True(t, texture.Frames[0].Rotated)
+	assert.Equal(t, "a.png", texture.Frames[1].FileName)
+	assert.Equal(t, jsonRectangle{jsonDimension{7, 8}, 5, 6}, texture.Frames[1].Frame)
+}
+
+func Test_unmarshalPackedTexturesArray(t *testing.T) {
+	data := `{
+		"frames": [
+			{"filename": "a.png", "frame": {"x": 5, "y": 6, "w": 7, "h": 8}, "sourceSize": {"w": 7, "h": 8}}
+		],
+		"meta": {"image": "sheet.png", "size": {"w": 64, "h": 32}, "scale": "1"}
+	}`
+	sheet, err := unmarshalPackedTextures(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Len(t, sheet.Textures, 1)
+	assert.Equal(t, "sheet.png", sheet.Textures[0].Image)
+	assert.Equal(t, 1.0, sheet.Textures[0].Scale)
+	assert.Equal(t, []Frame{{
+		FileName:   "a.png",
+		SourceSize: jsonDimension{7, 8},
+		Frame:      jsonRectangle{jsonDimension{7, 8}, 5, 6},
+	}}, sheet.Textures[0].Frames)
+}
+
+func Test_unmarshalPackedTexturesErrors(t *testing.T) {
+	for data, msg := range map[string]string{
+		`{"meta": {}}`:                           "sprite sheet contains neither textures nor frames",
+		`{"frames": "a.png"}`:                    "expected frames to be an object or an array but got a.png",
+		`{"frames": [], "meta": {"scale": 1}}`:   "",
+		`{"frames": [], "meta": {"scale": "x"}}`: `invalid scale "x"`,
+	} {
+		_, err := unmarshalPackedTextures(strings.NewReader(data))
+		if msg == "" {
+			assert.NoError(t, err, data)
+		} else {
+			assert.EqualError(t, err, msg, data)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package texturepacker
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+)
+
+// ValidationError is returned by Sheet.Validate, listing all problems found.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid sprite sheet: %s", strings.Join(e.Problems, ", "))
+}
+
+// Validate checks the Sheet against the images of its textures, which are loaded by Sheet.TextureImage. It reports
+// textures whose images can't be read or whose size differs from the one declared by the Sheet, frames exceeding the
+// bounds of their texture, frames overlapping each other and duplicate file names. Reading such frames would silently
+// return truncated or wrong images. The problems are returned as ValidationError.
+func (s *Sheet) Validate() error {
+	var problems []string
+	names := make(map[string]bool)
+	for i := range s.Textures {
+		texture := &s.Textures[i]
+		for _, frame := range texture.Frames {
+			if names[frame.FileName] {
+				problems = append(problems, fmt.Sprintf("duplicate frame %s", frame.FileName))
+			}
+			names[frame.FileName] = true
+		}
+
+		img, err := s.TextureImage(texture)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("could not read %s: %v", texture.Image, err))
+			continue
+		}
+		bounds := img.Bounds()
+		if size := bounds.Size(); size != texture.Size.Point() {
+			problems = append(problems, fmt.Sprintf("%s is %dx%d pixels but the sheet declares %dx%d", texture.Image,
+				size.X, size.Y, texture.Size.Width, texture.Size.Height))
+		}
+		for _, frame := range texture.Frames {
+			region := frame.Region()
+			if region.Empty() {
+				problems = append(problems, fmt.Sprintf("frame %s has no area", frame.FileName))
+			} else if !region.Add(bounds.Min).In(bounds) {
+				problems = append(problems, fmt.Sprintf("frame %s at %v exceeds %s of %dx%d pixels", frame.FileName,
+					region, texture.Image, bounds.Dx(), bounds.Dy()))
+			}
+		}
+		problems = append(problems, overlaps(texture)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{problems}
+	}
+	return nil
+}
+
+// overlaps returns a problem for every pair of frames of the texture whose regions overlap.
+func overlaps(texture *PackedTexture) []string {
+	type region struct {
+		name string
+		rect image.Rectangle
+	}
+	regions := make([]region, 0, len(texture.Frames))
+	for _, frame := range texture.Frames {
+		if r := frame.Region(); !r.Empty() {
+			regions = append(regions, region{frame.FileName, r})
+		}
+	}
+	// Sorted by their left edge, only the following regions starting before the right edge of a region can overlap it.
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].rect.Min.X < regions[j].rect.Min.X
+	})
+
+	var problems []string
+	for i, a := range regions {
+		for _, b := range regions[i+1:] {
+			if b.rect.Min.X >= a.rect.Max.X {
+				break
+			}
+			if a.rect.Overlaps(b.rect) {
+				problems = append(problems, fmt.Sprintf("frames %s and %s overlap in %s", a.name, b.name,
+					texture.Image))
+			}
+		}
+	}
+	return problems
+}
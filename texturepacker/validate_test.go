@@ -0,0 +1,40 @@
+package texturepacker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SheetValidate(t *testing.T) {
+	sheet, err := Open(writeTestSheet(t))
+	assert.NoError(t, err)
+	assert.NoError(t, sheet.Validate())
+
+	frames := sheet.Textures[0].Frames
+	sheet.Textures[0].Size = jsonDimension{16, 8}
+	// Antonio_01 now ends at (8, 8), which is still inside the texture.
+	frames[0].Frame.X = 5
+	frames[0].Frame.Y = 6
+	// Imelda_01 now overlaps Antonio_02 and exceeds the texture once rotated.
+	frames[2].Frame = jsonRectangle{jsonDimension{4, 9}, 1, 1}
+	frames[2].Rotated = true
+	sheet.Textures[0].Frames = append(frames, Frame{FileName: "Antonio_02.png"})
+
+	err = sheet.Validate()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{
+		"duplicate frame Antonio_02.png",
+		"sheet.png is 8x8 pixels but the sheet declares 16x8",
+		"frame Imelda_01.png at (1,1)-(10,5) exceeds sheet.png of 8x8 pixels",
+		"frame Antonio_02.png has no area",
+		"frames Antonio_02.png and Imelda_01.png overlap in sheet.png",
+	}, validationErr.Problems)
+
+	sheet, err = Open(writeTestSheet(t))
+	assert.NoError(t, err)
+	sheet.Textures[0].Image = "missing.png"
+	assert.Contains(t, sheet.Validate().Error(), "could not read missing.png")
+}
@@ -0,0 +1,40 @@
+package vampires
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+//go:generate go run ./internal/genkeys -o keys_gen.go
+
+// Key identifies a save file entry holding a value of type T. Keys of all entries mapped by SaveFile are predefined,
+// e.g. KeyCoins.
+type Key[T any] string
+
+// Get reads the value stored at the provided key from the SaveStorage, using the same codec as UnmarshalSave. This is
+// cheaper than loading the whole SaveFile if only a few values are needed.
+func Get[T any](db SaveStorage, key Key[T]) (T, error) {
+	var value T
+	data, err := GetRaw(db, string(key))
+	if err != nil {
+		return value, err
+	}
+	return value, unmarshalField(data, reflect.ValueOf(&value).Elem())
+}
+
+// ErrReadOnly is returned by Set for keys of fields tagged with the `readonly` option, e.g. KeyLifetimeCoins.
+var ErrReadOnly = errors.New("key is read-only")
+
+// Set writes the value to the provided key of the SaveStorage, using the same codec as MarshalSave. Just like
+// MarshalSave, it refuses to write the keys of fields tagged with the `readonly` option and returns ErrReadOnly instead.
+func Set[T any](db SaveStorage, key Key[T], value T) error {
+	if readOnlyKeys[string(key)] {
+		return fmt.Errorf("could not set %s: %w", key, ErrReadOnly)
+	}
+	data, err := marshalField(reflect.ValueOf(&value).Elem())
+	if err != nil {
+		return err
+	}
+	return db.Put(createKey(string(key)), createValue(data), nil)
+}
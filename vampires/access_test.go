@@ -0,0 +1,44 @@
+package vampires
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"testing"
+)
+
+func Test_GetSet(t *testing.T) {
+	db := NewMemoryStorage()
+	assert.NoError(t, Set(db, KeyCoins, 1337))
+	assert.NoError(t, Set(db, KeyUnlockedWeapons, []string{"WHIP"}))
+	assert.NoError(t, Set(db, KeyKillCount, map[string]int32{"BAT1": 2}))
+
+	coins, err := Get(db, KeyCoins)
+	assert.NoError(t, err)
+	assert.Equal(t, 1337.0, coins)
+
+	weapons, err := Get[[]string](db, "CapacitorStorage.UnlockedWeapons")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"WHIP"}, weapons)
+
+	// Values written by Set must be readable by UnmarshalSave and vice versa.
+	save := new(SaveFile)
+	assert.NoError(t, UnmarshalSave(db, save))
+	assert.Equal(t, 1337.0, save.Coins)
+	assert.Equal(t, map[string]int32{"BAT1": 2}, save.KillCount)
+
+	_, err = Get(db, KeyLanguage)
+	assert.ErrorIs(t, err, leveldb.ErrNotFound)
+}
+
+func Test_SetReadOnly(t *testing.T) {
+	db := NewMemoryStorage()
+	assert.NoError(t, PutRaw(db, "CapacitorStorage.LifetimeCoins", []byte("9000")))
+
+	err := Set(db, KeyLifetimeCoins, 1)
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.ErrorIs(t, Set[int32](db, "CapacitorStorage.LifetimeSurvived", 1), ErrReadOnly)
+
+	coins, err := Get(db, KeyLifetimeCoins)
+	assert.NoError(t, err)
+	assert.Equal(t, 9000.0, coins, "read-only keys must not be written")
+}
@@ -0,0 +1,51 @@
+package vampires
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+)
+
+var (
+	catalogWeapons = []string{
+		"AXE", "BONE", "CART2", "CHERRY", "CROSS", "DIAMOND", "FIREBALL", "FLOWER", "GARLIC", "GATTI", "GUNS", "GUNS2",
+		"HOLYBOOK", "HOLYWATER", "KNIFE", "LANCET", "LAROBBA", "LAUREL", "LIGHTNING", "MAGIC_MISSILE", "PENTAGRAM",
+		"SILF", "SILF2", "SONG", "TRAPANO", "VENTO", "WHIP",
+	}
+	catalogCharacters = []string{
+		"AMBROJOE", "ANTONIO", "ARCA", "CAVALLO", "CHRISTINE", "CLERICI", "CONCETTA", "DIVANO", "DOMMARIO", "EXDASH",
+		"GALLO", "GENNARO", "GIOVANNA", "IMELDA", "KROCHI", "LAMA", "MORTACCIO", "OSOLE", "PASQUALINA", "POE",
+		"POPPEA", "PORTA", "PUGNALA", "RAMBA", "SMITH", "TOASTIE",
+	}
+	catalogStages = []string{"CHAPEL", "FOREST", "LIBRARY", "TOWER", "WAREHOUSE"}
+)
+
+// Catalog maps the names of the SaveFile fields holding unlock lists to the IDs known to be valid for them. It is not
+// exhaustive, as every game update adds new content, so tools should offer the IDs already present in a save file as
+// well. Use LoadCatalog to extend it.
+var Catalog = map[string][]string{
+	"UnlockedWeapons":    catalogWeapons,
+	"CollectedWeapons":   catalogWeapons,
+	"UnlockedCharacters": catalogCharacters,
+	"BoughtCharacters":   catalogCharacters,
+	"UnlockedStages":     catalogStages,
+	"UnlockedHypers":     catalogStages,
+}
+
+// LoadCatalog reads a JSON object mapping field names to lists of IDs from the file at the provided path and adds them
+// to the Catalog.
+func LoadCatalog(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var extension map[string][]string
+	if err := json.Unmarshal(data, &extension); err != nil {
+		return err
+	}
+	for field, ids := range extension {
+		Catalog[field] = unionStrings(Catalog[field], ids)
+		sort.Strings(Catalog[field])
+	}
+	return nil
+}
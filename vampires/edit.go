@@ -0,0 +1,154 @@
+package vampires
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Clone returns a deep copy of the SaveFile. The copy can be stored just like the original.
+func (s *SaveFile) Clone() *SaveFile {
+	clone := *s
+	for _, field := range clone.Fields() {
+		switch value := field.Value.Interface().(type) {
+		case []string:
+			if value != nil {
+				field.Value.Set(reflect.ValueOf(append([]string{}, value...)))
+			}
+		case map[string]int32:
+			if value != nil {
+				m := make(map[string]int32, len(value))
+				for k, v := range value {
+					m[k] = v
+				}
+				field.Value.Set(reflect.ValueOf(m))
+			}
+		}
+	}
+	if s.original != nil {
+		clone.original = make(map[string][]byte, len(s.original))
+		for k, v := range s.original {
+			clone.original[k] = v
+		}
+	}
+	return &clone
+}
+
+// Change describes a field whose value differs between two save files.
+type Change struct {
+	Field    string
+	Old, New interface{}
+}
+
+// Diff returns the fields whose values differ between the SaveFile before and after a modification, in the order of
+// their declaration. Nil and empty slices or maps are considered equal.
+func Diff(before, after *SaveFile) []Change {
+	var changes []Change
+	afterFields := after.Fields()
+	for i, field := range before.Fields() {
+		a, b := field.Value, afterFields[i].Value
+		if (a.Kind() == reflect.Slice || a.Kind() == reflect.Map) && a.Len() == 0 && b.Len() == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			changes = append(changes, Change{field.Name, a.Interface(), b.Interface()})
+		}
+	}
+	return changes
+}
+
+// String describes the Change in a human-readable way. Slices are described by the added and removed entries, maps by
+// the changed entries.
+func (c Change) String() string {
+	switch old := c.Old.(type) {
+	case []string:
+		added, removed := sliceDiff(old, c.New.([]string))
+		var parts []string
+		for _, s := range added {
+			parts = append(parts, "+"+s)
+		}
+		for _, s := range removed {
+			parts = append(parts, "-"+s)
+		}
+		return fmt.Sprintf("%s: %s", c.Field, strings.Join(parts, " "))
+	case map[string]int32:
+		updated := c.New.(map[string]int32)
+		var keys []string
+		for key := range old {
+			keys = append(keys, key)
+		}
+		for key := range updated {
+			if _, ok := old[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		var parts []string
+		for _, key := range keys {
+			oldValue, hadOld := old[key]
+			newValue, hasNew := updated[key]
+			switch {
+			case !hasNew:
+				parts = append(parts, fmt.Sprintf("-%s", key))
+			case !hadOld:
+				parts = append(parts, fmt.Sprintf("+%s=%d", key, newValue))
+			case oldValue != newValue:
+				parts = append(parts, fmt.Sprintf("%s: %d -> %d", key, oldValue, newValue))
+			}
+		}
+		return fmt.Sprintf("%s: %s", c.Field, strings.Join(parts, ", "))
+	default:
+		return fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+	}
+}
+
+// sliceDiff returns the entries only present in b and the entries only present in a.
+func sliceDiff(a, b []string) (added, removed []string) {
+	inA := make(map[string]bool, len(a))
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+// BackupSaveFile copies the LevelDB of the save file located at the provided path into a sibling directory suffixed by
+// the current time, e.g. `leveldb.backup-20220110-180000`, and returns its path. Back up save files before modifying
+// them, as the game can't recover from broken ones.
+func BackupSaveFile(path string) (string, error) {
+	path = filepath.Clean(path)
+	backup := fmt.Sprintf("%s.backup-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Mkdir(backup, 0755); err != nil {
+		return "", err
+	}
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if !file.Type().IsRegular() || file.Name() == "LOCK" {
+			continue
+		}
+		if err := copyFile(filepath.Join(path, file.Name()), filepath.Join(backup, file.Name())); err != nil {
+			return "", err
+		}
+	}
+	return backup, nil
+}
@@ -0,0 +1,72 @@
+package vampires
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_SaveFileClone(t *testing.T) {
+	save := &SaveFile{UnlockedWeapons: []string{"WHIP"}, KillCount: map[string]int32{"BAT1": 1}, Coins: 5}
+	clone := save.Clone()
+	assert.Equal(t, save, clone)
+
+	clone.UnlockedWeapons[0] = "KNIFE"
+	clone.KillCount["BAT1"] = 2
+	assert.Equal(t, "WHIP", save.UnlockedWeapons[0])
+	assert.Equal(t, int32(1), save.KillCount["BAT1"])
+}
+
+func Test_Diff(t *testing.T) {
+	before := &SaveFile{
+		UnlockedWeapons: []string{"WHIP", "AXE"},
+		KillCount:       map[string]int32{"BAT1": 1, "GHOST": 4},
+		Coins:           5,
+		UnlockedStages:  []string{},
+	}
+	after := before.Clone()
+	after.UnlockedWeapons = []string{"WHIP", "KNIFE"}
+	after.KillCount["BAT1"] = 3
+	after.KillCount["SKELETON"] = 1
+	delete(after.KillCount, "GHOST")
+	after.Coins = 10
+	after.UnlockedStages = nil
+
+	changes := Diff(before, after)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, "UnlockedWeapons: +KNIFE -AXE", changes[0].String())
+	assert.Equal(t, "Coins: 5 -> 10", changes[1].String())
+	assert.Equal(t, "KillCount: BAT1: 1 -> 3, -GHOST, +SKELETON=1", changes[2].String())
+}
+
+func Test_BackupSaveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leveldb")
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Coins"), createValue([]byte("12")), nil))
+
+	backup, err := BackupSaveFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+	_, err = os.Stat(filepath.Join(backup, "LOCK"))
+	assert.True(t, os.IsNotExist(err))
+
+	save, db, err := OpenSaveFile(backup)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+	assert.Equal(t, 12.0, save.Coins)
+}
+
+func Test_LoadCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"UnlockedStages":["MOONSPELL","FOREST"]}`), 0644))
+
+	previous := Catalog["UnlockedStages"]
+	defer func() { Catalog["UnlockedStages"] = previous }()
+
+	assert.NoError(t, LoadCatalog(path))
+	assert.Contains(t, Catalog["UnlockedStages"], "MOONSPELL")
+	assert.Equal(t, len(previous)+1, len(Catalog["UnlockedStages"]))
+}
@@ -0,0 +1,99 @@
+package vampires
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Field describes a field of a SaveFile which is mapped to a save file entry.
+type Field struct {
+	// Name holds the name of the struct field, e.g. `Coins`.
+	Name string
+	// Key holds the key of the save file entry, e.g. `CapacitorStorage.Coins`.
+	Key string
+	// Value holds the settable value of the field.
+	Value reflect.Value
+	// Category groups related fields: `Unlocks` for the unlock and achievement lists, `Counters` for the per-key
+	// counters, `Settings` for user preferences and `Progress` for everything else.
+	Category string
+	// ReadOnly reports whether the field is tagged with the `readonly` option, which keeps it from being stored.
+	ReadOnly bool
+}
+
+// categoryOf returns the category of the SaveFile field with the provided name and kind.
+func categoryOf(name string, kind reflect.Kind) string {
+	switch {
+	case kind == reflect.Slice:
+		return "Unlocks"
+	case kind == reflect.Map:
+		return "Counters"
+	case settingsFields[name]:
+		return "Settings"
+	default:
+		return "Progress"
+	}
+}
+
+// Fields returns the fields of the SaveFile which are mapped to save file entries, in the order of their declaration.
+func (s *SaveFile) Fields() []Field {
+	elem := reflect.ValueOf(s).Elem()
+	var fields []Field
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		tag, ok := field.Tag.Lookup("vs_save")
+		if !ok {
+			continue
+		}
+		key, options, _ := parseTag(tag)
+		fields = append(fields, Field{field.Name, key, elem.Field(i), categoryOf(field.Name, field.Type.Kind()),
+			options.readOnly})
+	}
+	return fields
+}
+
+// Field returns the field of the SaveFile with the provided name or key.
+func (s *SaveFile) Field(name string) (Field, bool) {
+	for _, field := range s.Fields() {
+		if field.Name == name || field.Key == name {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// Validate checks the SaveFile for values the game can't handle, like negative coins or counters, or volumes outside of
+// the range from 0 to 1.
+func (s *SaveFile) Validate() error {
+	var problems []string
+	for _, field := range s.Fields() {
+		switch field.Value.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := field.Value.Float()
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				problems = append(problems, fmt.Sprintf("%s must be a finite number", field.Name))
+			} else if f < 0 {
+				problems = append(problems, fmt.Sprintf("%s must not be negative", field.Name))
+			} else if strings.HasSuffix(field.Name, "Volume") && f > 1 {
+				problems = append(problems, fmt.Sprintf("%s must not exceed 1", field.Name))
+			}
+		case reflect.Int32, reflect.Int64:
+			if field.Value.Int() < 0 {
+				problems = append(problems, fmt.Sprintf("%s must not be negative", field.Name))
+			}
+		case reflect.Map:
+			iter := field.Value.MapRange()
+			for iter.Next() {
+				if iter.Value().Int() < 0 {
+					problems = append(problems, fmt.Sprintf("%s of %s must not be negative", field.Name, iter.Key()))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid save file: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package vampires
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func Test_SaveFileFields(t *testing.T) {
+	save := new(SaveFile)
+	fields := save.Fields()
+	assert.Len(t, fields, 29)
+	assert.Equal(t, "Achievements", fields[0].Name)
+	assert.Equal(t, "CapacitorStorage.Achievements", fields[0].Key)
+	assert.Equal(t, "Unlocks", fields[0].Category)
+
+	field, ok := save.Field("CapacitorStorage.FlashingVFXEnabled")
+	assert.True(t, ok)
+	assert.Equal(t, "FlashingVfxEnabled", field.Name)
+	assert.Equal(t, "Settings", field.Category)
+
+	field, ok = save.Field("Coins")
+	assert.True(t, ok)
+	field.Value.SetFloat(12)
+	assert.Equal(t, 12.0, save.Coins)
+	assert.False(t, field.ReadOnly)
+
+	field, ok = save.Field("LifetimeSurvived")
+	assert.True(t, ok)
+	assert.True(t, field.ReadOnly)
+
+	_, ok = save.Field("Unknown")
+	assert.False(t, ok)
+}
+
+func Test_SaveFileValidate(t *testing.T) {
+	assert.NoError(t, (&SaveFile{Coins: 10, MusicVolume: 1, KillCount: map[string]int32{"BAT1": 1}}).Validate())
+	assert.Error(t, (&SaveFile{Coins: -1}).Validate())
+	assert.Error(t, (&SaveFile{LifetimeHeal: math.NaN()}).Validate())
+	assert.Error(t, (&SaveFile{SoundsVolume: 1.5}).Validate())
+	assert.Error(t, (&SaveFile{LifetimeSurvived: -3}).Validate())
+	assert.Error(t, (&SaveFile{KillCount: map[string]int32{"BAT1": -1}}).Validate())
+}
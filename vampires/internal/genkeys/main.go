@@ -0,0 +1,58 @@
+// genkeys generates the typed Key constants of the vampires package from the `vs_save` tags of vampires.SaveFile.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/hochbaum/vampire-survivors-tools/vampires"
+)
+
+func main() {
+	out := flag.String("o", "keys.go", "Specifies the output file.")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by genkeys; DO NOT EDIT.\n\n")
+	buf.WriteString("package vampires\n\n")
+	buf.WriteString("// Keys of the entries mapped by SaveFile, to be used with Get and Set.\n")
+	buf.WriteString("const (\n")
+
+	var readOnly []string
+	saveType := reflect.TypeOf(vampires.SaveFile{})
+	for i := 0; i < saveType.NumField(); i++ {
+		field := saveType.Field(i)
+		tag, ok := field.Tag.Lookup("vs_save")
+		if !ok {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		fmt.Fprintf(&buf, "\tKey%s Key[%s] = %q\n", field.Name, field.Type, options[0])
+		for _, option := range options[1:] {
+			if option == "readonly" {
+				readOnly = append(readOnly, fmt.Sprintf("Key%s", field.Name))
+			}
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString("// readOnlyKeys holds the keys of the fields tagged with `readonly`, which Set refuses to write.\n")
+	buf.WriteString("var readOnlyKeys = map[string]bool{\n")
+	for _, key := range readOnly {
+		fmt.Fprintf(&buf, "\tstring(%s): true,\n", key)
+	}
+	buf.WriteString("}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(*out, source, 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,43 @@
+// Code generated by genkeys; DO NOT EDIT.
+
+package vampires
+
+// Keys of the entries mapped by SaveFile, to be used with Get and Set.
+const (
+	KeyAchievements         Key[[]string]         = "CapacitorStorage.Achievements"
+	KeyBoughtCharacters     Key[[]string]         = "CapacitorStorage.BoughtCharacters"
+	KeyBoughtPowerups       Key[[]string]         = "CapacitorStorage.BoughtPowerups"
+	KeyCollectedItems       Key[[]string]         = "CapacitorStorage.CollectedItems"
+	KeyCollectedWeapons     Key[[]string]         = "CapacitorStorage.CollectedWeapons"
+	KeyUnlockedCharacters   Key[[]string]         = "CapacitorStorage.UnlockedCharacters"
+	KeyUnlockedHypers       Key[[]string]         = "CapacitorStorage.UnlockedHypers"
+	KeyUnlockedPowerUpRanks Key[[]string]         = "CapacitorStorage.UnlockedPowerUpRanks"
+	KeyUnlockedStages       Key[[]string]         = "CapacitorStorage.UnlockedStages"
+	KeyUnlockedWeapons      Key[[]string]         = "CapacitorStorage.UnlockedWeapons"
+	KeyCheatCodeUsed        Key[bool]             = "CapacitorStorage.CheatCodeUsed"
+	KeyDamageNumbersEnabled Key[bool]             = "CapacitorStorage.DamageNumbersEnabled"
+	KeyFlashingVfxEnabled   Key[bool]             = "CapacitorStorage.FlashingVFXEnabled"
+	KeyJoystickVisible      Key[bool]             = "CapacitorStorage.JoystickVisible"
+	KeySelectedHyper        Key[bool]             = "CapacitorStorage.SelectedHyper"
+	KeyStreamSafeEnabled    Key[bool]             = "CapacitorStorage.StreamSafeEnabled"
+	KeyLanguage             Key[string]           = "CapacitorStorage.Language"
+	KeySelectedCharacter    Key[string]           = "CapacitorStorage.SelectedCharacter"
+	KeySelectedStage        Key[string]           = "CapacitorStorage.SelectedStage"
+	KeyCoins                Key[float64]          = "CapacitorStorage.Coins"
+	KeyLifetimeCoins        Key[float64]          = "CapacitorStorage.LifetimeCoins"
+	KeyLifetimeHeal         Key[float64]          = "CapacitorStorage.LifetimeHeal"
+	KeyMusicVolume          Key[float64]          = "CapacitorStorage.MusicVolume"
+	KeySoundsVolume         Key[float64]          = "CapacitorStorage.SoundsVolume"
+	KeyBLuck                Key[int32]            = "CapacitorStorage.BLuck"
+	KeyLifetimeSurvived     Key[int32]            = "CapacitorStorage.LifetimeSurvived"
+	KeyDestroyedCount       Key[map[string]int32] = "CapacitorStorage.DestroyedCount"
+	KeyKillCount            Key[map[string]int32] = "CapacitorStorage.KillCount"
+	KeyPickupCount          Key[map[string]int32] = "CapacitorStorage.PickupCount"
+)
+
+// readOnlyKeys holds the keys of the fields tagged with `readonly`, which Set refuses to write.
+var readOnlyKeys = map[string]bool{
+	string(KeyLifetimeCoins):    true,
+	string(KeyLifetimeHeal):     true,
+	string(KeyLifetimeSurvived): true,
+}
@@ -0,0 +1,202 @@
+package vampires
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Side selects one of the two save files passed to Merge.
+type Side int
+
+const (
+	// SideA selects the first save file.
+	SideA Side = iota
+	// SideB selects the second save file.
+	SideB
+)
+
+// CountStrategy defines how Merge combines the per-key counters of KillCount, PickupCount and DestroyedCount.
+type CountStrategy int
+
+const (
+	// CountMax takes the higher counter of both save files.
+	CountMax CountStrategy = iota
+	// CountSum adds up the counters of both save files.
+	CountSum
+)
+
+// MergePolicy configures Merge.
+type MergePolicy struct {
+	Counts   CountStrategy
+	Settings Side
+}
+
+// MergeConflict describes a setting whose value differs between the merged save files.
+type MergeConflict struct {
+	Field  string
+	A, B   interface{}
+	Chosen Side
+}
+
+// MergeReport lists the conflicts resolved by Merge.
+type MergeReport struct {
+	Conflicts []MergeConflict
+}
+
+// settingsFields contains the names of the SaveFile fields which are user preferences rather than progress. These
+// cannot be combined, so Merge takes them from the side selected by MergePolicy.Settings.
+var settingsFields = map[string]bool{
+	"DamageNumbersEnabled": true,
+	"FlashingVfxEnabled":   true,
+	"JoystickVisible":      true,
+	"SelectedHyper":        true,
+	"StreamSafeEnabled":    true,
+	"Language":             true,
+	"SelectedCharacter":    true,
+	"SelectedStage":        true,
+	"MusicVolume":          true,
+	"SoundsVolume":         true,
+}
+
+// Merge combines the progress of two save files, for instance to sync progress between devices.
+//
+// Unlock and achievement slices are united, counters are combined according to MergePolicy.Counts and numeric
+// statistics like Coins or LifetimeCoins take the higher value of both sides. Flags like CheatCodeUsed are set if they
+// are set on either side. Settings are taken from the side selected by MergePolicy.Settings; every setting which
+// differs between both sides is listed in the returned MergeReport. Embedded structs and structs tagged with
+// `vs_save_prefix` are merged field by field. Merge fails on fields of other types, which it can't combine.
+//
+// The merged SaveFile is a new save file, so StoreSaveFile writes it as a whole, including the read-only lifetime
+// statistics.
+func Merge(a, b *SaveFile, policy MergePolicy) (*SaveFile, *MergeReport, error) {
+	merged := new(SaveFile)
+	report := new(MergeReport)
+	va, vb, vm := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), reflect.ValueOf(merged).Elem()
+	if err := mergeStruct(va, vb, vm, "", policy, report); err != nil {
+		return nil, nil, err
+	}
+	return merged, report, nil
+}
+
+// mergeStruct merges the exported fields of the structs a and b into m. The names of nested fields are prefixed by the
+// provided path, e.g. `Stats.`.
+func mergeStruct(va, vb, vm reflect.Value, path string, policy MergePolicy, report *MergeReport) error {
+	for i := 0; i < vm.NumField(); i++ {
+		field := vm.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := path + field.Name
+		fa, fb, fm := va.Field(i), vb.Field(i), vm.Field(i)
+
+		if settingsFields[name] {
+			chosen := fa
+			if policy.Settings == SideB {
+				chosen = fb
+			}
+			fm.Set(chosen)
+			if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+				report.Conflicts = append(report.Conflicts,
+					MergeConflict{name, fa.Interface(), fb.Interface(), policy.Settings})
+			}
+			continue
+		}
+
+		if _, nested := field.Tag.Lookup("vs_save_prefix"); nested || field.Anonymous {
+			if err := mergeNested(fa, fb, fm, name+".", policy, report); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch v := fa.Interface().(type) {
+		case []string:
+			fm.Set(reflect.ValueOf(unionStrings(v, fb.Interface().([]string))))
+			continue
+		case map[string]int32:
+			fm.Set(reflect.ValueOf(mergeCounts(v, fb.Interface().(map[string]int32), policy.Counts)))
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			fm.SetBool(fa.Bool() || fb.Bool())
+		case reflect.Float32, reflect.Float64:
+			fm.SetFloat(fa.Float())
+			if fb.Float() > fa.Float() {
+				fm.SetFloat(fb.Float())
+			}
+		case reflect.Int32, reflect.Int64:
+			fm.SetInt(fa.Int())
+			if fb.Int() > fa.Int() {
+				fm.SetInt(fb.Int())
+			}
+		case reflect.String:
+			fm.Set(fa)
+		default:
+			return fmt.Errorf("could not merge %s of unsupported type %s", name, field.Type)
+		}
+	}
+	return nil
+}
+
+// mergeNested merges the embedded or nested structs, or pointers to structs, a and b into m. Nil pointers are merged
+// like pointers to zero values.
+func mergeNested(fa, fb, fm reflect.Value, path string, policy MergePolicy, report *MergeReport) error {
+	if fm.Kind() == reflect.Ptr && fm.Type().Elem().Kind() == reflect.Struct {
+		if fa.IsNil() && fb.IsNil() {
+			return nil
+		}
+		zero := reflect.New(fm.Type().Elem())
+		if fa.IsNil() {
+			fa = zero
+		}
+		if fb.IsNil() {
+			fb = zero
+		}
+		fm.Set(reflect.New(fm.Type().Elem()))
+		fa, fb, fm = fa.Elem(), fb.Elem(), fm.Elem()
+	}
+	if fm.Kind() != reflect.Struct {
+		return fmt.Errorf("could not merge %s of unsupported type %s", strings.TrimSuffix(path, "."), fm.Type())
+	}
+	return mergeStruct(fa, fb, fm, path, policy, report)
+}
+
+// unionStrings returns the elements of both slices without duplicates, keeping the order in which they appear.
+func unionStrings(a, b []string) []string {
+	if a == nil && b == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	union := make([]string, 0, len(a)+len(b))
+	for _, s := range append(append([]string{}, a...), b...) {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		union = append(union, s)
+	}
+	return union
+}
+
+// mergeCounts combines the counters of both maps per key according to the provided CountStrategy.
+func mergeCounts(a, b map[string]int32, strategy CountStrategy) map[string]int32 {
+	if a == nil && b == nil {
+		return nil
+	}
+	merged := make(map[string]int32, len(a))
+	for key, count := range a {
+		merged[key] = count
+	}
+	for key, count := range b {
+		switch {
+		case strategy == CountSum:
+			merged[key] += count
+		case count > merged[key]:
+			merged[key] = count
+		}
+	}
+	return merged
+}
@@ -0,0 +1,112 @@
+package vampires
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"reflect"
+	"testing"
+)
+
+func Test_Merge(t *testing.T) {
+	desktop := &SaveFile{
+		UnlockedWeapons: []string{"WHIP", "KNIFE"},
+		KillCount:       map[string]int32{"BAT1": 10, "SKELETON": 3},
+		Coins:           100,
+		LifetimeCoins:   5000,
+		Language:        "en",
+		MusicVolume:     0.5,
+	}
+	deck := &SaveFile{
+		UnlockedWeapons: []string{"KNIFE", "AXE"},
+		KillCount:       map[string]int32{"BAT1": 4, "GHOST": 7},
+		Coins:           300,
+		LifetimeCoins:   1200,
+		CheatCodeUsed:   true,
+		Language:        "de",
+		MusicVolume:     0.5,
+	}
+
+	merged, report, err := Merge(desktop, deck, MergePolicy{Counts: CountSum, Settings: SideB})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"WHIP", "KNIFE", "AXE"}, merged.UnlockedWeapons)
+	assert.Equal(t, map[string]int32{"BAT1": 14, "SKELETON": 3, "GHOST": 7}, merged.KillCount)
+	assert.Equal(t, 300.0, merged.Coins)
+	assert.Equal(t, 5000.0, merged.LifetimeCoins)
+	assert.True(t, merged.CheatCodeUsed)
+	assert.Equal(t, "de", merged.Language)
+	assert.Nil(t, merged.PickupCount)
+	assert.Equal(t, []MergeConflict{{"Language", "en", "de", SideB}}, report.Conflicts)
+
+	merged, _, err = Merge(desktop, deck, MergePolicy{Counts: CountMax, Settings: SideA})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int32{"BAT1": 10, "SKELETON": 3, "GHOST": 7}, merged.KillCount)
+	assert.Equal(t, "en", merged.Language)
+}
+
+func Test_mergeStructNested(t *testing.T) {
+	type stats struct {
+		Kills map[string]int32 `vs_save:"Kills"`
+		Best  float64          `vs_save:"Best"`
+	}
+	type nestedSave struct {
+		Language string `vs_save:"Language"`
+		Stats    stats  `vs_save_prefix:"Stats."`
+		Extra    *stats `vs_save_prefix:"Extra."`
+	}
+	a := nestedSave{Language: "en", Stats: stats{map[string]int32{"BAT1": 2}, 10}}
+	b := nestedSave{Language: "en", Stats: stats{map[string]int32{"BAT1": 1, "GHOST": 4}, 30}, Extra: &stats{Best: 5}}
+
+	var merged nestedSave
+	report := new(MergeReport)
+	assert.NoError(t, mergeStruct(reflect.ValueOf(a), reflect.ValueOf(b), reflect.ValueOf(&merged).Elem(), "",
+		MergePolicy{Counts: CountSum}, report))
+	assert.Equal(t, map[string]int32{"BAT1": 3, "GHOST": 4}, merged.Stats.Kills)
+	assert.Equal(t, 30.0, merged.Stats.Best)
+	assert.Equal(t, &stats{Best: 5}, merged.Extra)
+	assert.Empty(t, report.Conflicts)
+}
+
+func Test_mergeStructUnsupported(t *testing.T) {
+	type unsupportedSave struct {
+		Scores []int `vs_save:"Scores"`
+	}
+	a := unsupportedSave{[]int{1}}
+	var merged unsupportedSave
+	assert.EqualError(t, mergeStruct(reflect.ValueOf(a), reflect.ValueOf(a), reflect.ValueOf(&merged).Elem(), "",
+		MergePolicy{}, new(MergeReport)), "could not merge Scores of unsupported type []int")
+}
+
+func Test_MergeStore(t *testing.T) {
+	open := func(path string) *SaveFile {
+		save, db, err := OpenSaveFile(path)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Close())
+		return save
+	}
+	create := func(lifetimeCoins, lifetimeHeal, lifetimeSurvived string) string {
+		path := t.TempDir()
+		db, err := leveldb.OpenFile(path, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, PutRaw(db, "CapacitorStorage.LifetimeCoins", []byte(lifetimeCoins)))
+		assert.NoError(t, PutRaw(db, "CapacitorStorage.LifetimeHeal", []byte(lifetimeHeal)))
+		assert.NoError(t, PutRaw(db, "CapacitorStorage.LifetimeSurvived", []byte(lifetimeSurvived)))
+		assert.NoError(t, db.Close())
+		return path
+	}
+
+	merged, _, err := Merge(open(create("5000", "10", "600")), open(create("1200", "40", "900")), MergePolicy{})
+	assert.NoError(t, err)
+
+	// The merged save file is stored into a fresh LevelDB, including the read-only lifetime statistics.
+	path := t.TempDir()
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	_, err = StoreSaveFile(merged, db)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	stored := open(path)
+	assert.Equal(t, 5000.0, stored.LifetimeCoins)
+	assert.Equal(t, 40.0, stored.LifetimeHeal)
+	assert.Equal(t, int32(900), stored.LifetimeSurvived)
+}
@@ -0,0 +1,147 @@
+package vampires
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ConversionReport lists which fields of a SaveFile could be mapped into a UnitySave and which could not.
+type ConversionReport struct {
+	Mapped   []string
+	Unmapped []UnmappedField
+	// Warnings lists problems of the converted UnitySave as a whole, like its checksum, which can't be computed.
+	Warnings []string
+}
+
+// Warnings of a ConversionReport about the checksum of the converted UnitySave.
+const (
+	warnStaleChecksum = "the checksum of the unity save is kept as it was and no longer matches the migrated " +
+		"fields, so the game may reject or reset the save file"
+	warnNoChecksum = "the unity save has no checksum, so the game may reject or reset the save file"
+)
+
+// UnmappedField describes a field which could not be mapped into a UnitySave.
+type UnmappedField struct {
+	Key    string
+	Reason string
+}
+
+// ConvertToUnity maps the fields of the provided legacy SaveFile into the UnitySave.
+//
+// The UnitySave serves as a template: if it is not empty, for instance because it was created by starting the Unity
+// build of the game once, only fields present in it are mapped and their JSON types must match. Fields of the template
+// which do not exist in SaveFile are kept untouched. An empty UnitySave accepts every field.
+//
+// The checksum of the UnitySave is not recomputed, as its algorithm is unknown. The ConversionReport warns about the
+// checksum being stale or missing, in which case the game may reject or reset the save file.
+func ConvertToUnity(save *SaveFile, doc *UnitySave) (*ConversionReport, error) {
+	taggedFields, err := scanStructTags(save, "vs_save")
+	if err != nil {
+		return nil, err
+	}
+
+	// The checksum is always present in stored saves and does not tell anything about their schema.
+	isTemplate := len(doc.Fields) > 0
+	if _, ok := doc.Fields[UnityChecksumField]; ok && len(doc.Fields) == 1 {
+		isTemplate = false
+	}
+
+	report := new(ConversionReport)
+	for key, field := range taggedFields {
+		// Read-only fields must not be edited, but they are still part of the save file being converted.
+		if field.omitted() {
+			continue
+		}
+
+		data, err := marshalField(field.value)
+		if err != nil {
+			report.Unmapped = append(report.Unmapped, UnmappedField{key, err.Error()})
+			continue
+		}
+
+		target := unityKey(key)
+		existing, ok := doc.Fields[target]
+		if isTemplate && !ok {
+			report.Unmapped = append(report.Unmapped, UnmappedField{key, "not present in the unity save"})
+			continue
+		}
+		if ok {
+			if want, got := jsonKind(existing), jsonKind(data); want != "null" && want != got {
+				report.Unmapped = append(report.Unmapped,
+					UnmappedField{key, fmt.Sprintf("unity save expects %s but got %s", want, got)})
+				continue
+			}
+		}
+
+		doc.Fields[target] = data
+		report.Mapped = append(report.Mapped, key)
+	}
+
+	if _, ok := doc.Fields[UnityChecksumField]; !ok {
+		report.Warnings = append(report.Warnings, warnNoChecksum)
+	} else if len(report.Mapped) > 0 {
+		report.Warnings = append(report.Warnings, warnStaleChecksum)
+	}
+
+	sort.Strings(report.Mapped)
+	sort.Slice(report.Unmapped, func(i, j int) bool {
+		return report.Unmapped[i].Key < report.Unmapped[j].Key
+	})
+	return report, nil
+}
+
+// MigrateToUnity opens the legacy LevelDB save file at legacyPath and stores its contents as Unity-era save file at
+// unityPath. If a save file already exists at unityPath, it is used as template for ConvertToUnity.
+func MigrateToUnity(legacyPath, unityPath string) (*ConversionReport, error) {
+	save, db, err := OpenSaveFile(legacyPath)
+	if err != nil {
+		return nil, err
+	}
+	// Nothing is written to the legacy save, so it can be closed right away.
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+
+	doc := NewUnitySave()
+	if file, err := os.Open(unityPath); err == nil {
+		doc, err = ParseUnitySave(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	report, err := ConvertToUnity(save, doc)
+	if err != nil {
+		return nil, err
+	}
+	return report, storeUnitySave(doc, unityPath)
+}
+
+// jsonKind returns the kind of the JSON value encoded in data.
+func jsonKind(data json.RawMessage) string {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return "null"
+	}
+	switch data[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
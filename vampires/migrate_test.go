@@ -0,0 +1,56 @@
+package vampires
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ConvertToUnity(t *testing.T) {
+	save := &SaveFile{Coins: 500, LifetimeCoins: 9000, UnlockedWeapons: []string{"WHIP"}, JoystickVisible: true}
+
+	doc := NewUnitySave()
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"Coins": 0,
+		"LifetimeCoins": 0,
+		"UnlockedWeapons": "WHIP",
+		"NewUnityField": 4,
+		"checksum": ""
+	}`), &doc.Fields))
+
+	report, err := ConvertToUnity(save, doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CapacitorStorage.Coins", "CapacitorStorage.LifetimeCoins"}, report.Mapped,
+		"read-only fields are converted as well")
+	assert.Contains(t, report.Unmapped, UnmappedField{"CapacitorStorage.JoystickVisible", "not present in the unity save"})
+	assert.Contains(t, report.Unmapped,
+		UnmappedField{"CapacitorStorage.UnlockedWeapons", "unity save expects string but got array"})
+
+	assert.JSONEq(t, `500`, string(doc.Fields["Coins"]))
+	assert.JSONEq(t, `9000`, string(doc.Fields["LifetimeCoins"]))
+	assert.JSONEq(t, `4`, string(doc.Fields["NewUnityField"]))
+	assert.Equal(t, []string{warnStaleChecksum}, report.Warnings)
+}
+
+func Test_MigrateToUnity(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath, unityPath := filepath.Join(dir, "leveldb"), filepath.Join(dir, "SaveData")
+
+	db, err := leveldb.OpenFile(legacyPath, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Coins"), createValue([]byte("4200")), nil))
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.KillCount"), createValue([]byte(`{"BAT1":3}`)), nil))
+	assert.NoError(t, db.Close())
+
+	report, err := MigrateToUnity(legacyPath, unityPath)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Unmapped)
+	assert.Equal(t, []string{warnNoChecksum}, report.Warnings)
+
+	save, _, err := OpenUnitySaveFile(unityPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 4200.0, save.Coins)
+	assert.Equal(t, map[string]int32{"BAT1": 3}, save.KillCount)
+}
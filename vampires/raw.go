@@ -0,0 +1,89 @@
+package vampires
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ListKeys returns the keys of all entries in the SaveStorage, stripped of their `_file://` prefix. Entries which do not
+// belong to the save file are skipped.
+func ListKeys(db SaveStorage) ([]string, error) {
+	iter := db.NewIterator(util.BytesPrefix(createKey("")), nil)
+	defer iter.Release()
+
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, parseKey(iter.Key()))
+	}
+	return keys, iter.Error()
+}
+
+// GetRaw returns the JSON encoded value stored at the provided key, stripped of its value prefix.
+func GetRaw(db SaveStorage, key string) ([]byte, error) {
+	data, err := db.Get(createKey(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("value of %s is missing its prefix", key)
+	}
+	return data[1:], nil
+}
+
+// PutRaw stores the JSON encoded value at the provided key, adding the key and value prefixes. The value must be valid
+// JSON, since the game fails to load the save file otherwise.
+func PutRaw(db SaveStorage, key string, value []byte) error {
+	if !json.Valid(value) {
+		return fmt.Errorf("value of %s is not valid JSON", key)
+	}
+	return db.Put(createKey(key), createValue(value), nil)
+}
+
+// DeleteKey deletes the entry stored at the provided key. Deleting a key which does not exist is not an error.
+func DeleteKey(db SaveStorage, key string) error {
+	return db.Delete(createKey(key), nil)
+}
+
+// memoryStorage implements SaveStorage in memory.
+type memoryStorage struct {
+	db *memdb.DB
+}
+
+// NewMemoryStorage creates a SaveStorage which is kept in memory, e.g. to work on a save file without touching the
+// LevelDB it was loaded from.
+func NewMemoryStorage() SaveStorage {
+	return &memoryStorage{memdb.New(comparer.DefaultComparer, 0)}
+}
+
+// Get implements SaveStorage.
+func (m *memoryStorage) Get(key []byte, _ *opt.ReadOptions) ([]byte, error) {
+	value, err := m.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, value...), nil
+}
+
+// Put implements SaveStorage.
+func (m *memoryStorage) Put(key []byte, value []byte, _ *opt.WriteOptions) error {
+	return m.db.Put(key, value)
+}
+
+// Delete implements SaveStorage.
+func (m *memoryStorage) Delete(key []byte, _ *opt.WriteOptions) error {
+	if err := m.db.Delete(key); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// NewIterator implements SaveStorage.
+func (m *memoryStorage) NewIterator(slice *util.Range, _ *opt.ReadOptions) iterator.Iterator {
+	return m.db.NewIterator(slice)
+}
@@ -0,0 +1,32 @@
+package vampires
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"testing"
+)
+
+func Test_RawKeys(t *testing.T) {
+	db := NewMemoryStorage()
+	assert.NoError(t, db.Put([]byte("META:_file://"), []byte("not part of the save"), nil))
+	assert.NoError(t, PutRaw(db, "CapacitorStorage.Coins", []byte("42")))
+	assert.NoError(t, PutRaw(db, "CapacitorStorage.Language", []byte(`"en"`)))
+	assert.Error(t, PutRaw(db, "CapacitorStorage.Language", []byte(`en`)))
+
+	keys, err := ListKeys(db)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CapacitorStorage.Coins", "CapacitorStorage.Language"}, keys)
+
+	stored, err := db.Get(createKey("CapacitorStorage.Coins"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, createValue([]byte("42")), stored)
+
+	value, err := GetRaw(db, "CapacitorStorage.Language")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`"en"`), value)
+
+	assert.NoError(t, DeleteKey(db, "CapacitorStorage.Language"))
+	assert.NoError(t, DeleteKey(db, "CapacitorStorage.Language"))
+	_, err = GetRaw(db, "CapacitorStorage.Language")
+	assert.ErrorIs(t, err, leveldb.ErrNotFound)
+}
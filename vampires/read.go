@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/syndtr/goleveldb/leveldb"
 	"reflect"
+	"sort"
 )
 
 // unmarshalFunc defines a function which takes data read from the LevelDB, converts it and unmarshalls it into the
@@ -30,37 +31,56 @@ var unmarshalers = map[reflect.Kind]unmarshalFunc{
 // UnmarshalSave reads the entries from the SaveStorage and unmarshalls them into the fields tagged with `vs_save` in the
 // provided interface.
 //
-// If a referenced LevelDB key could not be found in the database, this function does not return an error but prints a
-// warning, as new save files don't contain every possible key.
+// If a referenced LevelDB key could not be found in the database, this function does not return an error but leaves
+// the field untouched, as new save files don't contain every possible key. SaveFile.MissingKeys lists these keys for
+// save files opened by this package. Fields tagged with the `default=` option are set to their default value instead,
+// while fields tagged with the `required` option cause an error.
 func UnmarshalSave(db SaveStorage, i interface{}) error {
+	_, err := unmarshalSave(db, i)
+	return err
+}
+
+// unmarshalSave implements UnmarshalSave and additionally returns the keys which are missing from the SaveStorage and
+// have no default value.
+func unmarshalSave(db SaveStorage, i interface{}) ([]string, error) {
 	taggedFields, err := scanStructTags(i, "vs_save")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for key, value := range taggedFields {
+	var missing []string
+	for key, field := range taggedFields {
 		data, err := db.Get(createKey(key), nil)
 		if err == leveldb.ErrNotFound {
-			fmt.Printf("warning: ignoring field tagged with %s as it is not present in the levelDB\n", key)
+			if err := field.unmarshalMissing(key, "levelDB"); err != nil {
+				return nil, err
+			}
+			if !field.options.hasDefault {
+				missing = append(missing, key)
+			}
 			continue
 		} else if err != nil {
-			return err
-		}
-
-		data = data[1:]
-		fieldType := value.Type().Kind()
-
-		unmarshaler, ok := unmarshalers[fieldType]
-		if !ok {
-			return fmt.Errorf("could not find suitable unmarshaler for type %s", fieldType)
+			return nil, err
 		}
 
-		if err := unmarshaler(data, value); err != nil {
-			return err
+		if err := unmarshalField(data[1:], field.value); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// unmarshalField looks up the unmarshalFunc matching the type of the provided reflect.Value and uses it to unmarshal
+// the JSON encoded data into it.
+func unmarshalField(data []byte, v reflect.Value) error {
+	fieldType := v.Type().Kind()
+	unmarshaler, ok := unmarshalers[fieldType]
+	if !ok {
+		return fmt.Errorf("could not find suitable unmarshaler for type %s", fieldType)
+	}
+	return unmarshaler(data, v)
 }
 
 // unmarshalStringSlice reads a string slice from the save file and assigns it to a struct field.
@@ -2,7 +2,9 @@ package vampires
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 	"reflect"
 	"testing"
 )
@@ -23,6 +25,15 @@ func (m *mockSaveStorage) Put([]byte, []byte, *opt.WriteOptions) error {
 	return nil
 }
 
+func (m *mockSaveStorage) Delete([]byte, *opt.WriteOptions) error {
+	m.t.Errorf("Delete(...) should not have been called by a read operation")
+	return nil
+}
+
+func (m *mockSaveStorage) NewIterator(*util.Range, *opt.ReadOptions) iterator.Iterator {
+	return iterator.NewEmptyIterator(nil)
+}
+
 func Test_UnmarshalSave(t *testing.T) {
 	fields := []struct {
 		Key    string
@@ -62,3 +73,46 @@ func Test_UnmarshalSave(t *testing.T) {
 		assert.Equal(t, elem.Field(i).Interface(), fields[i].Output)
 	}
 }
+
+func Test_UnmarshalSaveTagOptions(t *testing.T) {
+	db := NewMemoryStorage()
+	assert.NoError(t, PutRaw(db, "present", []byte(`"value"`)))
+
+	s := struct {
+		Present  string   `vs_save:"present,required"`
+		Language string   `vs_save:"language,default=en"`
+		Volume   float64  `vs_save:"volume,default=0.5"`
+		Weapons  []string `vs_save:"weapons,default=[\"WHIP\",\"KNIFE\"]"`
+	}{}
+	assert.NoError(t, UnmarshalSave(db, &s))
+	assert.Equal(t, "value", s.Present)
+	assert.Equal(t, "en", s.Language)
+	assert.Equal(t, 0.5, s.Volume)
+	assert.Equal(t, []string{"WHIP", "KNIFE"}, s.Weapons)
+
+	required := struct {
+		Missing string `vs_save:"missing,required"`
+	}{}
+	assert.Error(t, UnmarshalSave(db, &required))
+}
+
+func Test_unmarshalSaveMissingKeys(t *testing.T) {
+	db := NewMemoryStorage()
+	assert.NoError(t, PutRaw(db, "present", []byte(`"value"`)))
+
+	s := struct {
+		Present  string  `vs_save:"present"`
+		Language string  `vs_save:"language,default=en"`
+		Volume   float64 `vs_save:"volume"`
+		Coins    float64 `vs_save:"coins"`
+	}{}
+	missing, err := unmarshalSave(db, &s)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"coins", "volume"}, missing)
+
+	doc := NewUnitySave()
+	doc.Fields["present"] = []byte(`"value"`)
+	missing, err = unmarshalUnitySave(doc, &s)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"coins", "volume"}, missing)
+}
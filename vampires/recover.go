@@ -0,0 +1,319 @@
+package vampires
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	lerrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/journal"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/table"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RecoveryMethod describes how RecoverSave got hold of the contents of a save file.
+type RecoveryMethod string
+
+const (
+	// RecoveryIntact means the save file was not damaged and could be opened as is.
+	RecoveryIntact RecoveryMethod = "intact"
+	// RecoveryRepaired means the LevelDB could be repaired using leveldb.RecoverFile.
+	RecoveryRepaired RecoveryMethod = "repaired"
+	// RecoverySalvaged means the LevelDB could not be repaired and the entries were salvaged from its table and journal
+	// files.
+	RecoverySalvaged RecoveryMethod = "salvaged"
+)
+
+// ErrNothingSalvaged is returned by RecoverSave if not a single entry could be salvaged from a damaged save file.
+var ErrNothingSalvaged = errors.New("no entries could be salvaged from the save file")
+
+// RecoveryReport describes the outcome of RecoverSave.
+type RecoveryReport struct {
+	Method RecoveryMethod
+
+	// Salvaged contains the keys whose values could be recovered.
+	Salvaged []string
+	// Lost contains the keys which are missing or whose values could not be decoded anymore.
+	Lost []string
+
+	// entries holds the raw values of all `CapacitorStorage.*` entries which could be read, mapped by their keys.
+	entries map[string][]byte
+}
+
+// WriteEntries writes every entry recovered by RecoverSave into the provided SaveStorage as it was read, including the
+// ones which are not part of SaveFile or tagged with the `readonly` option, like the lifetime statistics. Values which
+// are not valid JSON anymore are skipped, as the game fails to load them. Use it to fill a fresh LevelDB replacing the
+// damaged one.
+func (r *RecoveryReport) WriteEntries(db SaveStorage) error {
+	keys := make([]string, 0, len(r.entries))
+	for key := range r.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := r.entries[key]
+		if len(value) == 0 || !json.Valid(value[1:]) {
+			continue
+		}
+		if err := db.Put(createKey(key), value, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecoverSave reads a possibly damaged save file located at the provided path and reconstructs the best possible
+// SaveFile from it.
+//
+// If the LevelDB reports corruption when opening it, it is repaired in place using leveldb.RecoverFile. If that fails as
+// well, every readable `CapacitorStorage.*` entry is salvaged from the `.ldb` and `.log` files of the LevelDB, without
+// modifying them. Fields which could not be recovered keep their zero value and are listed in the RecoveryReport.
+// Use RecoveryReport.WriteEntries to write the recovered entries into a fresh LevelDB replacing the damaged one. Errors
+// other than corruption, e.g. if the LevelDB is locked by the running game, are returned as they are.
+func RecoverSave(path string) (*SaveFile, *RecoveryReport, error) {
+	db, err := leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true})
+	method := RecoveryIntact
+	var source SaveStorage = db
+	if err != nil {
+		// Other errors, like the lock held by the running game or missing permissions, don't mean the save is damaged.
+		if !lerrors.IsCorrupted(err) {
+			return nil, nil, err
+		}
+		db, err = leveldb.RecoverFile(path, nil)
+		method = RecoveryRepaired
+		source = db
+		if err != nil {
+			if source, err = salvageEntries(path); err != nil {
+				return nil, nil, err
+			}
+			method = RecoverySalvaged
+		}
+	}
+	if method != RecoverySalvaged {
+		defer db.Close()
+	}
+
+	save := new(SaveFile)
+	report, err := unmarshalSalvaged(source, save)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Method = method
+	if report.entries, err = readEntries(source); err != nil {
+		return nil, nil, err
+	}
+	return save, report, nil
+}
+
+// readEntries returns the raw values of all `CapacitorStorage.*` entries of the SaveStorage, mapped by their keys.
+func readEntries(db SaveStorage) (map[string][]byte, error) {
+	iter := db.NewIterator(util.BytesPrefix(createKey(unityKeyPrefix)), nil)
+	defer iter.Release()
+
+	entries := make(map[string][]byte)
+	for iter.Next() {
+		entries[parseKey(iter.Key())] = append([]byte{}, iter.Value()...)
+	}
+	return entries, iter.Error()
+}
+
+// unmarshalSalvaged unmarshalls every field of the provided interface it can and reports which ones it could not.
+func unmarshalSalvaged(db SaveStorage, i interface{}) (*RecoveryReport, error) {
+	taggedFields, err := scanStructTags(i, "vs_save")
+	if err != nil {
+		return nil, err
+	}
+
+	report := new(RecoveryReport)
+	for key, field := range taggedFields {
+		data, err := GetRaw(db, key)
+		if err == nil {
+			err = unmarshalField(data, field.value)
+		}
+		if err != nil {
+			field.value.Set(reflect.Zero(field.value.Type()))
+			if field.options.hasDefault {
+				if err := field.applyDefault(key); err != nil {
+					return nil, err
+				}
+			}
+			report.Lost = append(report.Lost, key)
+			continue
+		}
+		report.Salvaged = append(report.Salvaged, key)
+	}
+
+	sort.Strings(report.Salvaged)
+	sort.Strings(report.Lost)
+	return report, nil
+}
+
+// salvagedEntry is an entry read from the files of a LevelDB.
+type salvagedEntry struct {
+	seq     uint64
+	deleted bool
+	value   []byte
+}
+
+// salvageEntries reads all entries it can from the table and journal files of the LevelDB located at the provided path
+// and returns the latest version of each of them in a memory storage.
+func salvageEntries(path string) (SaveStorage, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]salvagedEntry)
+	collect := func(key []byte, entry salvagedEntry) {
+		if !bytes.HasPrefix(key, createKey(unityKeyPrefix)) {
+			return
+		}
+		if existing, ok := entries[string(key)]; !ok || entry.seq >= existing.seq {
+			entries[string(key)] = entry
+		}
+	}
+
+	for _, file := range files {
+		name := filepath.Join(path, file.Name())
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".ldb", ".sst":
+			err = salvageTable(name, collect)
+		case ".log":
+			// The LOG files of the LevelDB itself are human readable logs and don't contain any entries.
+			if strings.HasPrefix(file.Name(), "LOG") {
+				continue
+			}
+			err = salvageJournal(name, collect)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrNothingSalvaged
+	}
+
+	db := NewMemoryStorage()
+	for key, entry := range entries {
+		if entry.deleted {
+			continue
+		}
+		if err := db.Put([]byte(key), entry.value, nil); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// salvageTable reads the entries of the table file at the provided path, skipping corrupted blocks.
+func salvageTable(path string, collect func([]byte, salvagedEntry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	o := &opt.Options{Strict: opt.NoStrict}
+	reader, err := table.NewReader(file, info.Size(), storage.FileDesc{Type: storage.TypeTable}, nil, nil, o)
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+
+	iter := reader.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		// Table files contain internal keys, which consist of the actual key followed by 8 bytes holding the sequence
+		// number and the type of the entry.
+		key := iter.Key()
+		if len(key) < 8 {
+			continue
+		}
+		trailer := binary.LittleEndian.Uint64(key[len(key)-8:])
+		collect(append([]byte{}, key[:len(key)-8]...), salvagedEntry{
+			seq:     trailer >> 8,
+			deleted: trailer&0xff == 0,
+			value:   append([]byte{}, iter.Value()...),
+		})
+	}
+	return nil
+}
+
+// salvageJournal reads the entries of the journal file at the provided path, skipping corrupted records.
+func salvageJournal(path string, collect func([]byte, salvagedEntry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := journal.NewReader(file, nil, false, true)
+	for {
+		record, err := reader.Next()
+		if err != nil {
+			// Either the end of the journal has been reached or it is damaged beyond the point of recovery.
+			return nil
+		}
+
+		data, err := io.ReadAll(record)
+		if err != nil {
+			continue
+		}
+		decodeBatch(data, collect)
+	}
+}
+
+// decodeBatch decodes the entries of a batch record of a LevelDB journal. Batches start with a 12 bytes header holding
+// the sequence number of the first entry and the number of entries, followed by the entries, each consisting of its type
+// and its length-prefixed key and value.
+func decodeBatch(data []byte, collect func([]byte, salvagedEntry)) {
+	if len(data) < 12 {
+		return
+	}
+	seq := binary.LittleEndian.Uint64(data)
+	count := binary.LittleEndian.Uint32(data[8:])
+	data = data[12:]
+
+	readBytes := func() ([]byte, bool) {
+		n, read := binary.Uvarint(data)
+		if read <= 0 || uint64(len(data)-read) < n {
+			return nil, false
+		}
+		b := data[read : read+int(n)]
+		data = data[read+int(n):]
+		return b, true
+	}
+
+	for i := uint32(0); i < count && len(data) > 0; i++ {
+		deleted := data[0] == 0
+		data = data[1:]
+
+		key, ok := readBytes()
+		if !ok {
+			return
+		}
+		var value []byte
+		if !deleted {
+			if value, ok = readBytes(); !ok {
+				return
+			}
+		}
+		collect(append([]byte{}, key...), salvagedEntry{seq + uint64(i), deleted, append([]byte{}, value...)})
+	}
+}
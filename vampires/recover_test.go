@@ -0,0 +1,118 @@
+package vampires
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	lerrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createRecoveryTestDB(t *testing.T) string {
+	path := t.TempDir()
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Coins"), createValue([]byte("100")), nil))
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Language"), createValue([]byte(`"en"`)), nil))
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.LifetimeCoins"), createValue([]byte("9000")), nil))
+	// Compacting moves the entries written so far into a table file, the following ones stay in the journal.
+	assert.NoError(t, db.CompactRange(util.Range{}))
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Coins"), createValue([]byte("250")), nil))
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.KillCount"), createValue([]byte(`{"BAT1":`)), nil))
+	assert.NoError(t, db.Close())
+	return path
+}
+
+func Test_RecoverSave(t *testing.T) {
+	path := createRecoveryTestDB(t)
+
+	save, report, err := RecoverSave(path)
+	assert.NoError(t, err)
+	assert.Equal(t, RecoveryIntact, report.Method)
+	assert.Equal(t, 250.0, save.Coins)
+	assert.Equal(t, "en", save.Language)
+	assert.Equal(t, []string{"CapacitorStorage.Coins", "CapacitorStorage.Language", "CapacitorStorage.LifetimeCoins"},
+		report.Salvaged)
+	assert.Contains(t, report.Lost, "CapacitorStorage.KillCount")
+}
+
+func Test_RecoveryReportWriteEntries(t *testing.T) {
+	path := createRecoveryTestDB(t)
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Unknown"), createValue([]byte("1")), nil))
+	assert.NoError(t, db.Close())
+
+	_, report, err := RecoverSave(path)
+	assert.NoError(t, err)
+
+	recovered := NewMemoryStorage()
+	assert.NoError(t, report.WriteEntries(recovered))
+	keys, err := ListKeys(recovered)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CapacitorStorage.Coins", "CapacitorStorage.Language", "CapacitorStorage.LifetimeCoins",
+		"CapacitorStorage.Unknown"}, keys, "invalid values must be skipped")
+
+	// The read-only lifetime statistics survive the recovery.
+	save := new(SaveFile)
+	assert.NoError(t, UnmarshalSave(recovered, save))
+	assert.Equal(t, 9000.0, save.LifetimeCoins)
+	assert.Equal(t, 250.0, save.Coins)
+}
+
+func Test_RecoverSaveRepaired(t *testing.T) {
+	path := createRecoveryTestDB(t)
+
+	// Without the manifest, the LevelDB does not know about its files anymore.
+	manifests, err := filepath.Glob(filepath.Join(path, "MANIFEST-*"))
+	assert.NoError(t, err)
+	for _, manifest := range append(manifests, filepath.Join(path, "CURRENT")) {
+		assert.NoError(t, os.Remove(manifest))
+	}
+
+	save, report, err := RecoverSave(path)
+	assert.NoError(t, err)
+	assert.Equal(t, RecoveryRepaired, report.Method)
+	assert.Equal(t, 250.0, save.Coins)
+	assert.Equal(t, "en", save.Language)
+}
+
+func Test_salvageEntries(t *testing.T) {
+	path := createRecoveryTestDB(t)
+
+	db, err := salvageEntries(path)
+	assert.NoError(t, err)
+
+	save := new(SaveFile)
+	report, err := unmarshalSalvaged(db, save)
+	assert.NoError(t, err)
+	assert.Equal(t, 250.0, save.Coins)
+	assert.Equal(t, "en", save.Language)
+	assert.Equal(t, []string{"CapacitorStorage.Coins", "CapacitorStorage.Language", "CapacitorStorage.LifetimeCoins"},
+		report.Salvaged)
+	assert.Contains(t, report.Lost, "CapacitorStorage.KillCount")
+
+	garbage := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(garbage, "000001.log"), []byte("garbage"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(garbage, "000002.ldb"), []byte("garbage"), 0644))
+	_, err = salvageEntries(garbage)
+	assert.ErrorIs(t, err, ErrNothingSalvaged)
+}
+
+func Test_RecoverSaveLocked(t *testing.T) {
+	path := createRecoveryTestDB(t)
+
+	// The game holds the lock of the LevelDB while it is running, which must not be mistaken for damage.
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, _, err = RecoverSave(path)
+	assert.Error(t, err)
+	assert.False(t, lerrors.IsCorrupted(err))
+
+	_, _, err = RecoverSave(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
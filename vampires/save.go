@@ -21,22 +21,31 @@ var marshalers = map[reflect.Kind]marshalFunc{
 	reflect.Map:     marshalStringToIntMap,
 }
 
-// MarshalSave serializes save file wrapper provided and returns a SerializedSaveFile handle.
+// MarshalSave serializes save file wrapper provided and returns a SerializedSaveFile handle. Fields tagged with the
+// `readonly` option, as well as empty fields tagged with the `omitempty` option, are skipped.
 func MarshalSave(i interface{}) (*SerializedSaveFile, error) {
+	return marshalSave(i, false)
+}
+
+// MarshalSaveAll serializes the save file wrapper provided just like MarshalSave, but keeps the fields tagged with the
+// `readonly` option. Use it to copy save files as a whole, e.g. into snapshots, rather than to write them back.
+func MarshalSaveAll(i interface{}) (*SerializedSaveFile, error) {
+	return marshalSave(i, true)
+}
+
+// marshalSave implements MarshalSave and MarshalSaveAll, keeping the fields tagged with `readonly` if readOnly is set.
+func marshalSave(i interface{}, readOnly bool) (*SerializedSaveFile, error) {
 	serialized := new(SerializedSaveFile)
 	taggedFields, err := scanStructTags(i, "vs_save")
 	if err != nil {
 		return nil, err
 	}
 
-	for key, value := range taggedFields {
-		fieldType := value.Type().Kind()
-		marshaler, ok := marshalers[fieldType]
-		if !ok {
-			return nil, fmt.Errorf("could not find suitable marshaler for type %s", fieldType)
+	for key, field := range taggedFields {
+		if field.omitted() || field.options.readOnly && !readOnly {
+			continue
 		}
-
-		data, err := marshaler(value)
+		data, err := marshalField(field.value)
 		if err != nil {
 			return nil, err
 		}
@@ -46,6 +55,16 @@ func MarshalSave(i interface{}) (*SerializedSaveFile, error) {
 	return serialized, nil
 }
 
+// marshalField looks up the marshalFunc matching the type of the provided reflect.Value and uses it to serialize it.
+func marshalField(v reflect.Value) ([]byte, error) {
+	fieldType := v.Type().Kind()
+	marshaler, ok := marshalers[fieldType]
+	if !ok {
+		return nil, fmt.Errorf("could not find suitable marshaler for type %s", fieldType)
+	}
+	return marshaler(v)
+}
+
 // writeSaveToDB writes a SerializedSaveFile to the provided LevelDB.
 func writeSaveToDB(serialized *SerializedSaveFile, db SaveStorage) error {
 	for _, entry := range serialized.Entries {
@@ -54,3 +54,21 @@ func setStructField(target interface{}, index int, value interface{}) {
 func createSerializedSaveFileEntry(key, value string) SerializedSaveFileEntry {
 	return SerializedSaveFileEntry{Key: createKey(key), Value: createValue([]byte(value))}
 }
+
+func Test_MarshalSaveTagOptions(t *testing.T) {
+	s := &struct {
+		Empty    []string `vs_save:"empty,omitempty"`
+		Zero     int32    `vs_save:"zero,omitempty"`
+		NonEmpty int32    `vs_save:"nonEmpty,omitempty"`
+		ReadOnly float64  `vs_save:"readOnly,readonly"`
+	}{Empty: []string{}, NonEmpty: 3, ReadOnly: 100}
+
+	actual, err := MarshalSave(s)
+	assert.NoError(t, err)
+	assert.Equal(t, []SerializedSaveFileEntry{createSerializedSaveFileEntry("nonEmpty", "3")}, actual.Entries)
+
+	actual, err = MarshalSaveAll(s)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []SerializedSaveFileEntry{createSerializedSaveFileEntry("nonEmpty", "3"),
+		createSerializedSaveFileEntry("readOnly", "100")}, actual.Entries)
+}
@@ -1,10 +1,19 @@
 package vampires
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 // SaveFile wraps the contents of a Vampire Survivors save file.
@@ -36,17 +45,28 @@ type SaveFile struct {
 	SelectedStage     string `vs_save:"CapacitorStorage.SelectedStage"`
 
 	Coins         float64 `vs_save:"CapacitorStorage.Coins"`
-	LifetimeCoins float64 `vs_save:"CapacitorStorage.LifetimeCoins"`
-	LifetimeHeal  float64 `vs_save:"CapacitorStorage.LifetimeHeal"`
+	LifetimeCoins float64 `vs_save:"CapacitorStorage.LifetimeCoins,readonly"`
+	LifetimeHeal  float64 `vs_save:"CapacitorStorage.LifetimeHeal,readonly"`
 	MusicVolume   float64 `vs_save:"CapacitorStorage.MusicVolume"`
 	SoundsVolume  float64 `vs_save:"CapacitorStorage.SoundsVolume"`
 
 	BLuck            int32 `vs_save:"CapacitorStorage.BLuck"`
-	LifetimeSurvived int32 `vs_save:"CapacitorStorage.LifetimeSurvived"`
+	LifetimeSurvived int32 `vs_save:"CapacitorStorage.LifetimeSurvived,readonly"`
 
 	DestroyedCount map[string]int32 `vs_save:"CapacitorStorage.DestroyedCount"`
 	KillCount      map[string]int32 `vs_save:"CapacitorStorage.KillCount"`
 	PickupCount    map[string]int32 `vs_save:"CapacitorStorage.PickupCount"`
+
+	// original holds the raw values the SaveFile was loaded with, before migrating them, mapped by their keys. It is
+	// used by StoreSaveFile to only write keys which have changed.
+	original map[string][]byte
+	// obsolete holds the keys which were removed by migrating the SaveFile, e.g. by renaming them. StoreSaveFile deletes
+	// them.
+	obsolete []string
+	// schema holds the version of the Schema the SaveFile was loaded from.
+	schema string
+	// missing holds the keys which were not present in the save file the SaveFile was loaded from.
+	missing []string
 }
 
 // SerializedSaveFileEntry defines a serialized entry of a Vampire Survivors save file. Its fields follow the rules
@@ -60,53 +80,308 @@ type SerializedSaveFile struct {
 	Entries []SerializedSaveFileEntry
 }
 
+// Values returns the JSON encoded values of the entries, stripped of their prefixes and mapped by their keys.
+func (s *SerializedSaveFile) Values() map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage, len(s.Entries))
+	for _, entry := range s.Entries {
+		values[parseKey(entry.Key)] = entry.Value[1:]
+	}
+	return values
+}
+
 // OpenSaveFile opens a Vampire Survivors save file located at the provided path and returns it wrapped in a SaveFile
 // instance, as well as the LevelDB itself, which must be closed by the user.
+//
+// Save files written by older versions of the game are migrated to the layout of SaveFile, see Schemas. The migration
+// only happens in memory, the LevelDB is not touched until the SaveFile is stored by StoreSaveFile, which writes the
+// migrated values and deletes the keys removed by the migration.
 func OpenSaveFile(path string) (*SaveFile, *leveldb.DB, error) {
 	db, err := leveldb.OpenFile(path, nil)
 	if err != nil {
 		return nil, nil, err
 	}
-	save := new(SaveFile)
-	return save, db, UnmarshalSave(db, save)
+	save, err := openSaveFile(db, Schemas)
+	return save, db, err
+}
+
+// openSaveFile implements OpenSaveFile, migrating the save file using the provided schemas.
+func openSaveFile(db SaveStorage, schemas []Schema) (*SaveFile, error) {
+	migrated, schema, err := migrateSave(db, schemas)
+	if err != nil {
+		return nil, err
+	}
+	save := &SaveFile{schema: schema.Version}
+	if save.missing, err = unmarshalSave(migrated, save); err != nil {
+		return nil, err
+	}
+
+	// The original values are the ones stored in the LevelDB rather than the migrated ones, so StoreSaveFile writes
+	// every value changed by the migration.
+	taggedFields, err := scanStructTags(save, "vs_save")
+	if err != nil {
+		return nil, err
+	}
+	save.original = make(map[string][]byte, len(taggedFields))
+	for key := range taggedFields {
+		data, err := db.Get(createKey(key), nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		save.original[key] = data
+	}
+
+	keys, err := ListKeys(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if data, _ := db.Get(createKey(key), nil); len(data) == 0 {
+			continue
+		}
+		if _, err := migrated.Get(createKey(key), nil); err == leveldb.ErrNotFound {
+			save.obsolete = append(save.obsolete, key)
+		}
+	}
+	return save, nil
+}
+
+// OpenSaveFileReadOnly reads the Vampire Survivors save file located at the provided path without locking it, so it
+// can be read while the game is running. The files of the LevelDB are copied into a temporary directory for that, which
+// is removed afterwards. As there is no LevelDB to write to, the returned SaveFile can't be stored.
+func OpenSaveFileReadOnly(path string) (*SaveFile, error) {
+	var err error
+	// The game might write to the LevelDB while it is being copied, which leaves an inconsistent copy behind. Trying
+	// again usually resolves that.
+	for attempt := 0; attempt < 3; attempt++ {
+		var save *SaveFile
+		if save, err = readSnapshot(path); err == nil {
+			return save, nil
+		}
+	}
+	return nil, err
+}
+
+// readSnapshot copies the LevelDB located at the provided path into a temporary directory and reads the SaveFile from
+// the copy.
+func readSnapshot(path string) (*SaveFile, error) {
+	tmp, err := os.MkdirTemp("", "vampires-save-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if !file.Type().IsRegular() || file.Name() == "LOCK" {
+			continue
+		}
+		if err := copyFile(filepath.Join(path, file.Name()), filepath.Join(tmp, file.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := leveldb.OpenFile(tmp, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	migrated, schema, err := migrateSave(db, Schemas)
+	if err != nil {
+		return nil, err
+	}
+	save := &SaveFile{schema: schema.Version}
+	save.missing, err = unmarshalSave(migrated, save)
+	return save, err
 }
 
-// StoreSaveFile writes the SaveFile to the provided LevelDB, which you can obtain by using OpenSaveFile.
-func StoreSaveFile(save *SaveFile, db SaveStorage) error {
-	serialized, err := MarshalSave(save)
+// copyFile copies the file at src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
 	if err != nil {
 		return err
 	}
-	return writeSaveToDB(serialized, db)
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// MissingKeys returns the keys of the fields which were not present in the save file the SaveFile was loaded from, if
+// it was loaded by this package. These fields hold their zero value, as new save files don't contain every key.
+func (s *SaveFile) MissingKeys() []string {
+	return s.missing
+}
+
+// SchemaVersion returns the version of the Schema the SaveFile was written with, if it was loaded using OpenSaveFile.
+func (s *SaveFile) SchemaVersion() string {
+	return s.schema
+}
+
+// StoreSaveFile writes the SaveFile to the provided LevelDB, which you can obtain by using OpenSaveFile, and returns the
+// keys which have been written or deleted.
+//
+// If the SaveFile was loaded using OpenSaveFile, only keys whose serialized value has changed are written, so untouched
+// keys keep their original encoding, and fields tagged with `readonly` are never written back. Keys which were missing
+// from the LevelDB are only written once they are set to a non-zero value. Keys which were removed by migrating the
+// SaveFile are deleted. A SaveFile created by other means, e.g. by Merge, is written as a whole, including the fields
+// tagged with `readonly`, as it is a copy rather than an edit of a save file.
+func StoreSaveFile(save *SaveFile, db SaveStorage) ([]string, error) {
+	taggedFields, err := scanStructTags(save, "vs_save")
+	if err != nil {
+		return nil, err
+	}
+	serialized, err := marshalSave(save, save.original == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := new(SerializedSaveFile)
+	var keys []string
+	for _, entry := range serialized.Entries {
+		key := parseKey(entry.Key)
+		if save.unchanged(key, entry.Value, taggedFields[key].value) {
+			continue
+		}
+		changed.Entries = append(changed.Entries, entry)
+		keys = append(keys, key)
+	}
+
+	if err := writeSaveToDB(changed, db); err != nil {
+		return nil, err
+	}
+	if save.original != nil {
+		for _, entry := range changed.Entries {
+			save.original[parseKey(entry.Key)] = entry.Value
+		}
+	}
+	for _, key := range save.obsolete {
+		if err := db.Delete(createKey(key), nil); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	save.obsolete = nil
+
+	sort.Strings(keys)
+	return keys, nil
 }
 
-// scanStructTags collects the reflect.Value s tagged by the provided tag in the provided struct. It maps the key of the
-// struct tag to its respective reflect.Value.
-func scanStructTags(i interface{}, tag string) (map[string]reflect.Value, error) {
+// unchanged reports whether the serialized value of the field tagged with the provided key still matches the value the
+// SaveFile was loaded with.
+func (s *SaveFile) unchanged(key string, value []byte, field reflect.Value) bool {
+	if s.original == nil {
+		return false
+	}
+
+	original, ok := s.original[key]
+	if !ok {
+		return field.IsZero()
+	}
+	if bytes.Equal(original, value) {
+		return true
+	}
+
+	// The game does not necessarily encode values the way this package does, e.g. floats or the order of map keys, so
+	// compare the re-encoded original value as well.
+	decoded := reflect.New(field.Type()).Elem()
+	if err := unmarshalField(original[1:], decoded); err != nil {
+		return false
+	}
+	canonical, err := marshalField(decoded)
+	return err == nil && bytes.Equal(createValue(canonical), value)
+}
+
+// scanStructTags collects the fields tagged by the provided tag in the provided struct. It maps the key of the struct
+// tag to its respective taggedField, holding the reflect.Value of the field and the options of the tag.
+//
+// Embedded structs and nested structs tagged with the provided tag suffixed by `_prefix`, e.g. `vs_save_prefix`, are
+// scanned as well. The keys of their fields are prefixed by the value of that tag, which allows composing large save
+// models out of smaller structs. Nil pointers to such structs are allocated.
+func scanStructTags(i interface{}, tag string) (map[string]taggedField, error) {
 	elem := reflect.ValueOf(i).Elem()
 	if !elem.CanAddr() {
 		return nil, fmt.Errorf("input type must be a pointer type")
 	}
 
-	result := make(map[string]reflect.Value)
+	result := make(map[string]taggedField)
+	return result, scanStruct(elem, tag, "", result)
+}
+
+// scanStruct implements scanStructTags by collecting the tagged fields of the provided struct into result, prefixing
+// their keys by the provided prefix.
+func scanStruct(elem reflect.Value, tag, prefix string, result map[string]taggedField) error {
 	for i := 0; i < elem.NumField(); i++ {
 		field := elem.Type().Field(i)
 		value := elem.Field(i)
 
-		key, ok := field.Tag.Lookup(tag)
+		tagValue, ok := field.Tag.Lookup(tag)
 		if !ok {
+			nestedPrefix, nested := field.Tag.Lookup(tag + "_prefix")
+			if !nested && !field.Anonymous {
+				continue
+			}
+			if err := scanNestedStruct(field, value, tag, prefix+nestedPrefix, result); err != nil {
+				return err
+			}
 			continue
 		}
-		result[key] = value
+
+		key, options, err := parseTag(tagValue)
+		if err != nil {
+			return err
+		}
+		key = prefix + key
+		if _, exists := result[key]; exists {
+			return fmt.Errorf("key %s is tagged more than once", key)
+		}
+		result[key] = taggedField{value, options}
 	}
 
-	return result, nil
+	return nil
+}
+
+// scanNestedStruct scans the embedded or nested struct stored in the provided field.
+func scanNestedStruct(field reflect.StructField, value reflect.Value, tag, prefix string,
+	result map[string]taggedField) error {
+	if value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct {
+		if value.IsNil() {
+			if !value.CanSet() {
+				return fmt.Errorf("could not allocate nested struct %s", field.Name)
+			}
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		// Embedded types which aren't structs can't contain tagged fields.
+		if field.Anonymous {
+			return nil
+		}
+		return fmt.Errorf("field %s tagged with %s_prefix must be a struct", field.Name, tag)
+	}
+	return scanStruct(value, tag, prefix, result)
 }
 
 // SaveStorage defines a wrapper for leveldb.DB.
 type SaveStorage interface {
 	Get(key []byte, ro *opt.ReadOptions) ([]byte, error)
 	Put(key []byte, value []byte, wo *opt.WriteOptions) error
+	Delete(key []byte, wo *opt.WriteOptions) error
+	NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator
 }
 
 // createKey formats and serializes the provided string to be a valid LevelDB key.
@@ -114,6 +389,11 @@ func createKey(key string) []byte {
 	return []byte("_file://\x00\x01" + key)
 }
 
+// parseKey extracts the key of a struct tag from the provided LevelDB key. It is the inverse of createKey.
+func parseKey(key []byte) string {
+	return strings.TrimPrefix(string(key), string(createKey("")))
+}
+
 // createValue formats the provided bytes to be a valid LevelDB value.
 func createValue(value []byte) []byte {
 	return append([]byte{'\x01'}, value...)
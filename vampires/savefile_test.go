@@ -2,6 +2,7 @@ package vampires
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
 	"reflect"
 	"testing"
 )
@@ -36,16 +37,136 @@ func Test_scanStructKeys(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
-func createTestStructForScanning() (interface{}, map[string]reflect.Value) {
+func createTestStructForScanning() (interface{}, map[string]taggedField) {
 	s := &struct {
 		Field1 string   `test:"field1"`
-		Field2 int32    `test:"field2"`
-		Field3 []string `test:"field3"`
+		Field2 int32    `test:"field2,omitempty,readonly"`
+		Field3 []string `test:"field3,required,default=[\"a\",\"b\"]"`
 	}{}
-	vals := map[string]reflect.Value{
-		"field1": reflect.ValueOf(s).Elem().Field(0),
-		"field2": reflect.ValueOf(s).Elem().Field(1),
-		"field3": reflect.ValueOf(s).Elem().Field(2),
+	vals := map[string]taggedField{
+		"field1": {reflect.ValueOf(s).Elem().Field(0), tagOptions{}},
+		"field2": {reflect.ValueOf(s).Elem().Field(1), tagOptions{omitEmpty: true, readOnly: true}},
+		"field3": {reflect.ValueOf(s).Elem().Field(2), tagOptions{
+			required:     true,
+			hasDefault:   true,
+			defaultValue: `["a","b"]`,
+		}},
 	}
 	return s, vals
 }
+
+func Test_scanStructTagsUnknownOption(t *testing.T) {
+	s := &struct {
+		Field1 string `test:"field1,omitmepty"`
+	}{}
+	_, err := scanStructTags(s, "test")
+	assert.Error(t, err)
+}
+
+func Test_StoreSaveFile(t *testing.T) {
+	path := t.TempDir()
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Coins"), createValue([]byte("1e3")), nil))
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Language"), createValue([]byte(`"en"`)), nil))
+	assert.NoError(t, db.Close())
+
+	save, db, err := OpenSaveFile(path)
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, "initial", save.SchemaVersion())
+
+	written, err := StoreSaveFile(save, db)
+	assert.NoError(t, err)
+	assert.Empty(t, written, "unchanged keys must not be written")
+
+	save.Language = "de"
+	save.BLuck = 12
+	save.LifetimeHeal = 12
+	written, err = StoreSaveFile(save, db)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CapacitorStorage.BLuck", "CapacitorStorage.Language"}, written,
+		"read-only keys must not be written")
+
+	coins, err := db.Get(createKey("CapacitorStorage.Coins"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, createValue([]byte("1e3")), coins, "untouched keys must keep their encoding")
+
+	written, err = StoreSaveFile(save, db)
+	assert.NoError(t, err)
+	assert.Empty(t, written)
+}
+
+func Test_StoreSaveFileCopy(t *testing.T) {
+	// SaveFiles which were not loaded from a LevelDB are copies, which keep their read-only fields.
+	db := NewMemoryStorage()
+	written, err := StoreSaveFile(&SaveFile{Coins: 10, LifetimeCoins: 500}, db)
+	assert.NoError(t, err)
+	assert.Contains(t, written, "CapacitorStorage.LifetimeCoins")
+
+	stored := new(SaveFile)
+	assert.NoError(t, UnmarshalSave(db, stored))
+	assert.Equal(t, 500.0, stored.LifetimeCoins)
+}
+
+func Test_scanStructTagsNested(t *testing.T) {
+	type Settings struct {
+		Language string `test:"Language"`
+	}
+	type Stats struct {
+		Coins float64 `test:"Coins"`
+	}
+	s := &struct {
+		Settings
+		Stats  *Stats `test_prefix:"Stats."`
+		Extra  string `test:"Extra"`
+		Ignore Settings
+	}{}
+
+	actual, err := scanStructTags(s, "test")
+	assert.NoError(t, err)
+	assert.Len(t, actual, 3)
+	assert.Contains(t, actual, "Language")
+	assert.Contains(t, actual, "Stats.Coins")
+	assert.Contains(t, actual, "Extra")
+	assert.NotNil(t, s.Stats, "nil pointers to nested structs must be allocated")
+
+	actual["Stats.Coins"].value.SetFloat(42)
+	assert.Equal(t, 42.0, s.Stats.Coins)
+
+	duplicate := &struct {
+		Settings
+		Language string `test:"Language"`
+	}{}
+	_, err = scanStructTags(duplicate, "test")
+	assert.Error(t, err)
+}
+
+func Test_UnmarshalSaveEmbedded(t *testing.T) {
+	db := NewMemoryStorage()
+	assert.NoError(t, PutRaw(db, "CapacitorStorage.Coins", []byte("42")))
+	assert.NoError(t, PutRaw(db, "Custom.Notes", []byte(`"gg"`)))
+
+	s := &struct {
+		SaveFile
+		Custom struct {
+			Notes string `vs_save:"Notes"`
+		} `vs_save_prefix:"Custom."`
+	}{}
+	assert.NoError(t, UnmarshalSave(db, s))
+	assert.Equal(t, 42.0, s.Coins)
+	assert.Equal(t, "gg", s.Custom.Notes)
+}
+
+func Test_OpenSaveFileReadOnly(t *testing.T) {
+	path := t.TempDir()
+	db, err := leveldb.OpenFile(path, nil)
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.NoError(t, db.Put(createKey("CapacitorStorage.Coins"), createValue([]byte("77")), nil))
+
+	// The LevelDB is still opened and locked, just like it is while the game is running.
+	save, err := OpenSaveFileReadOnly(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 77.0, save.Coins)
+}
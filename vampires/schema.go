@@ -0,0 +1,193 @@
+package vampires
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Migration upgrades the values of a save file, mapped by their keys, from one Schema to the next.
+type Migration interface {
+	Migrate(values map[string]json.RawMessage) error
+}
+
+// RenameKey is a Migration moving the value of a key to another key.
+type RenameKey struct {
+	From, To string
+}
+
+// Migrate implements Migration.
+func (m RenameKey) Migrate(values map[string]json.RawMessage) error {
+	value, ok := values[m.From]
+	if !ok {
+		return nil
+	}
+	if _, exists := values[m.To]; exists {
+		return fmt.Errorf("could not rename %s as %s already exists", m.From, m.To)
+	}
+	values[m.To] = value
+	delete(values, m.From)
+	return nil
+}
+
+// ChangeType is a Migration converting the value of a key, e.g. from a number to a string.
+type ChangeType struct {
+	Key     string
+	Convert func(value json.RawMessage) (json.RawMessage, error)
+}
+
+// Migrate implements Migration.
+func (m ChangeType) Migrate(values map[string]json.RawMessage) error {
+	value, ok := values[m.Key]
+	if !ok {
+		return nil
+	}
+	converted, err := m.Convert(value)
+	if err != nil {
+		return fmt.Errorf("could not change type of %s: %w", m.Key, err)
+	}
+	values[m.Key] = converted
+	return nil
+}
+
+// SplitMap is a Migration moving entries of a string to int map into other maps. Target returns the key of the map an
+// entry is moved to, or an empty string to keep it in place.
+type SplitMap struct {
+	Key    string
+	Target func(entry string) string
+}
+
+// Migrate implements Migration.
+func (m SplitMap) Migrate(values map[string]json.RawMessage) error {
+	value, ok := values[m.Key]
+	if !ok {
+		return nil
+	}
+
+	var source map[string]int32
+	if err := json.Unmarshal(value, &source); err != nil {
+		return fmt.Errorf("could not split %s: %w", m.Key, err)
+	}
+
+	maps := map[string]map[string]int32{m.Key: {}}
+	for entry, count := range source {
+		target := m.Target(entry)
+		if target == "" {
+			target = m.Key
+		}
+		if maps[target] == nil {
+			merged := make(map[string]int32)
+			if existing, ok := values[target]; ok {
+				if err := json.Unmarshal(existing, &merged); err != nil {
+					return fmt.Errorf("could not split %s into %s: %w", m.Key, target, err)
+				}
+			}
+			maps[target] = merged
+		}
+		maps[target][entry] += count
+	}
+
+	for key, entries := range maps {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		values[key] = data
+	}
+	return nil
+}
+
+// Schema describes the layout of save files written by a version of the game.
+type Schema struct {
+	// Version names the game update which introduced the schema.
+	Version string
+	// Markers are keys introduced by the schema. Save files containing all of them were written by this or a later
+	// version of the game.
+	Markers []string
+	// Migrations upgrade save files of the previous Schema to this one.
+	Migrations []Migration
+}
+
+// Schemas contains the known layouts of save files in ascending order. The last one is the layout matching SaveFile,
+// older save files are migrated to it by OpenSaveFile.
+//
+// No game update known so far renamed or retyped keys, so the schemas only tell save files apart and don't migrate
+// anything yet. When an update does, append a new Schema with the respective Migrations and update SaveFile.
+var Schemas = []Schema{
+	{
+		Version: "initial",
+	},
+	{
+		Version: "hyper-mode",
+		Markers: []string{"CapacitorStorage.UnlockedHypers"},
+	},
+}
+
+// DetectSchema returns the Schema of the save file stored in the provided SaveStorage, based on the keys present.
+func DetectSchema(db SaveStorage) (Schema, error) {
+	keys, err := ListKeys(db)
+	if err != nil {
+		return Schema{}, err
+	}
+	return Schemas[detectSchema(Schemas, keys)], nil
+}
+
+// detectSchema returns the index of the newest of the provided schemas whose markers are all part of the keys.
+func detectSchema(schemas []Schema, keys []string) int {
+	present := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		present[key] = true
+	}
+
+	detected := 0
+	for i, schema := range schemas {
+		matches := true
+		for _, marker := range schema.Markers {
+			matches = matches && present[marker]
+		}
+		if matches {
+			detected = i
+		}
+	}
+	return detected
+}
+
+// migrateSave reads every entry of the provided SaveStorage, migrates them from their detected schema to the newest one
+// of the provided schemas and returns them in a memory storage, along with the detected schema. The provided SaveStorage
+// is not modified.
+func migrateSave(db SaveStorage, schemas []Schema) (SaveStorage, Schema, error) {
+	values := make(map[string]json.RawMessage)
+	iter := db.NewIterator(util.BytesPrefix(createKey("")), nil)
+	for iter.Next() {
+		if len(iter.Value()) == 0 {
+			continue
+		}
+		values[parseKey(iter.Key())] = append(json.RawMessage{}, iter.Value()[1:]...)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, Schema{}, err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	detected := detectSchema(schemas, keys)
+
+	for _, schema := range schemas[detected+1:] {
+		for _, migration := range schema.Migrations {
+			if err := migration.Migrate(values); err != nil {
+				return nil, Schema{}, fmt.Errorf("could not migrate to %s: %w", schema.Version, err)
+			}
+		}
+	}
+
+	migrated := NewMemoryStorage()
+	for key, value := range values {
+		if err := migrated.Put(createKey(key), createValue(value), nil); err != nil {
+			return nil, Schema{}, err
+		}
+	}
+	return migrated, schemas[detected], nil
+}
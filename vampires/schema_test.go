@@ -0,0 +1,116 @@
+package vampires
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb"
+	"strings"
+	"testing"
+)
+
+func Test_detectSchema(t *testing.T) {
+	schemas := []Schema{
+		{Version: "v1"},
+		{Version: "v2", Markers: []string{"a"}},
+		{Version: "v3", Markers: []string{"a", "b"}},
+	}
+	assert.Equal(t, 0, detectSchema(schemas, nil))
+	assert.Equal(t, 1, detectSchema(schemas, []string{"a", "c"}))
+	assert.Equal(t, 2, detectSchema(schemas, []string{"b", "a"}))
+}
+
+func Test_migrateSave(t *testing.T) {
+	schemas := []Schema{
+		{Version: "v1"},
+		{
+			Version: "v2",
+			Markers: []string{"Stage"},
+			Migrations: []Migration{
+				RenameKey{"SelectedStage", "Stage"},
+				ChangeType{"Luck", func(value json.RawMessage) (json.RawMessage, error) {
+					return json.Marshal(string(value))
+				}},
+			},
+		},
+		{
+			Version: "v3",
+			Markers: []string{"BossKills"},
+			Migrations: []Migration{
+				SplitMap{"Kills", func(entry string) string {
+					if strings.HasPrefix(entry, "BOSS_") {
+						return "BossKills"
+					}
+					return ""
+				}},
+			},
+		},
+	}
+
+	db := NewMemoryStorage()
+	assert.NoError(t, PutRaw(db, "SelectedStage", []byte(`"FOREST"`)))
+	assert.NoError(t, PutRaw(db, "Luck", []byte(`3`)))
+	assert.NoError(t, PutRaw(db, "Kills", []byte(`{"BAT":4,"BOSS_REAPER":1}`)))
+
+	migrated, schema, err := migrateSave(db, schemas)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", schema.Version)
+
+	expected := map[string]string{
+		"Stage":     `"FOREST"`,
+		"Luck":      `"3"`,
+		"Kills":     `{"BAT":4}`,
+		"BossKills": `{"BOSS_REAPER":1}`,
+	}
+	keys, err := ListKeys(migrated)
+	assert.NoError(t, err)
+	assert.Len(t, keys, len(expected))
+	for key, value := range expected {
+		actual, err := GetRaw(migrated, key)
+		assert.NoError(t, err)
+		assert.JSONEq(t, value, string(actual), key)
+	}
+
+	// The source of the migration must stay untouched.
+	_, err = GetRaw(db, "SelectedStage")
+	assert.NoError(t, err)
+
+	assert.NoError(t, PutRaw(db, "BossKills", []byte(`{}`)))
+	_, schema, err = migrateSave(db, schemas)
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", schema.Version, "markers of v2 are missing but newer schemas take precedence")
+}
+
+func Test_openSaveFileMigratesOnStore(t *testing.T) {
+	schemas := []Schema{
+		{Version: "v1"},
+		{
+			Version:    "v2",
+			Markers:    []string{"CapacitorStorage.SelectedStage"},
+			Migrations: []Migration{RenameKey{"CapacitorStorage.Stage", "CapacitorStorage.SelectedStage"}},
+		},
+	}
+	db := NewMemoryStorage()
+	assert.NoError(t, PutRaw(db, "CapacitorStorage.Stage", []byte(`"FOREST"`)))
+	assert.NoError(t, PutRaw(db, "CapacitorStorage.Coins", []byte(`10`)))
+
+	save, err := openSaveFile(db, schemas)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", save.SchemaVersion())
+	assert.Equal(t, "FOREST", save.SelectedStage)
+
+	keys, err := StoreSaveFile(save, db)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CapacitorStorage.SelectedStage", "CapacitorStorage.Stage"}, keys)
+	_, err = GetRaw(db, "CapacitorStorage.Stage")
+	assert.ErrorIs(t, err, leveldb.ErrNotFound)
+
+	reopened, err := openSaveFile(db, schemas)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", reopened.SchemaVersion())
+	assert.Equal(t, "FOREST", reopened.SelectedStage)
+	assert.Equal(t, 10.0, reopened.Coins)
+
+	keys, err = StoreSaveFile(reopened, db)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,102 @@
+package vampires
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagOptions holds the comma-separated options following the key of a `vs_save` struct tag.
+//
+//   - omitempty: the field is not written if it holds its zero value or an empty slice or map.
+//   - required:  reading fails if the key is missing from the save file.
+//   - readonly:  the field is read but never written back, e.g. for lifetime statistics.
+//   - default=:  the JSON encoded value used if the key is missing from the save file. Strings may omit their quotes.
+//     As the value may contain commas itself, this must be the last option.
+type tagOptions struct {
+	omitEmpty    bool
+	required     bool
+	readOnly     bool
+	hasDefault   bool
+	defaultValue string
+}
+
+// taggedField is a struct field tagged with the key of a save file entry.
+type taggedField struct {
+	value   reflect.Value
+	options tagOptions
+}
+
+// parseTag splits a struct tag into its key and its options.
+func parseTag(tag string) (string, tagOptions, error) {
+	var options tagOptions
+	parts := strings.SplitN(tag, ",", 2)
+	key := parts[0]
+	for len(parts) == 2 {
+		rest := parts[1]
+		if strings.HasPrefix(rest, "default=") {
+			parts = []string{rest}
+		} else {
+			parts = strings.SplitN(rest, ",", 2)
+		}
+		option := parts[0]
+
+		switch {
+		case option == "omitempty":
+			options.omitEmpty = true
+		case option == "required":
+			options.required = true
+		case option == "readonly":
+			options.readOnly = true
+		case strings.HasPrefix(option, "default="):
+			options.hasDefault = true
+			options.defaultValue = strings.TrimPrefix(option, "default=")
+		default:
+			return "", options, fmt.Errorf("unknown option %q in tag of %s", option, key)
+		}
+	}
+	return key, options, nil
+}
+
+// skipMarshal reports whether the field must not be written to the save file.
+func (f taggedField) skipMarshal() bool {
+	return f.options.readOnly || f.omitted()
+}
+
+// omitted reports whether the field is empty and tagged with the `omitempty` option.
+func (f taggedField) omitted() bool {
+	if !f.options.omitEmpty {
+		return false
+	}
+	switch f.value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return f.value.Len() == 0
+	default:
+		return f.value.IsZero()
+	}
+}
+
+// unmarshalMissing handles a field whose key is missing from the save file, by either applying its default value or
+// returning an error if it is required. Other fields are left untouched, the callers collect their keys instead.
+func (f taggedField) unmarshalMissing(key, source string) error {
+	switch {
+	case f.options.required:
+		return fmt.Errorf("required field tagged with %s is not present in the %s", key, source)
+	case f.options.hasDefault:
+		return f.applyDefault(key)
+	}
+	return nil
+}
+
+// applyDefault unmarshalls the default value of the field into it.
+func (f taggedField) applyDefault(key string) error {
+	data := []byte(f.options.defaultValue)
+	if f.value.Kind() == reflect.String && !json.Valid(data) {
+		data, _ = json.Marshal(f.options.defaultValue)
+	}
+	if err := unmarshalField(data, f.value); err != nil {
+		return fmt.Errorf("invalid default value for %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package vampires
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UnityChecksumField is the name of the field holding the checksum of a Unity-era save file.
+const UnityChecksumField = "checksum"
+
+// unityKeyPrefix is the prefix of the LevelDB keys which is not part of the keys used by Unity-era save files.
+const unityKeyPrefix = "CapacitorStorage."
+
+// UnitySave wraps the JSON document of a Unity-era Vampire Survivors save file.
+//
+// Since moving off Electron, the game stores its saves as a single JSON file named `SaveData`, located at
+// `%USERPROFILE%/AppData/LocalLow/poncle/Vampire Survivors/Saves`. The document keys equal the LevelDB keys of the
+// legacy save format without the `CapacitorStorage.` prefix, which allows both formats to be mapped onto SaveFile.
+// The document holds a `checksum` field whose algorithm is unknown. It is neither validated nor recomputed, but kept as
+// it was read, so the game may reject saves whose contents were changed.
+type UnitySave struct {
+	Fields map[string]json.RawMessage
+}
+
+// NewUnitySave creates an empty UnitySave.
+func NewUnitySave() *UnitySave {
+	return &UnitySave{Fields: make(map[string]json.RawMessage)}
+}
+
+// ParseUnitySave reads a Unity-era save file from the provided reader. The checksum is not verified.
+func ParseUnitySave(r io.Reader) (*UnitySave, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	save := NewUnitySave()
+	if err := json.Unmarshal(data, &save.Fields); err != nil {
+		return nil, err
+	}
+	return save, nil
+}
+
+// Checksum returns the checksum stored in the document.
+func (u *UnitySave) Checksum() string {
+	var checksum string
+	_ = json.Unmarshal(u.Fields[UnityChecksumField], &checksum)
+	return checksum
+}
+
+// WriteTo serializes the document and writes it to the provided writer. The checksum is written as it is.
+func (u *UnitySave) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.Marshal(u.Fields)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// unityKey converts a LevelDB key into the respective key of a Unity-era save file.
+func unityKey(key string) string {
+	return strings.TrimPrefix(key, unityKeyPrefix)
+}
+
+// UnmarshalUnitySave reads the fields of the UnitySave and unmarshalls them into the fields tagged with `vs_save` in
+// the provided interface, using the same codec as UnmarshalSave.
+//
+// Just like UnmarshalSave, fields missing from the document are left untouched instead of causing an error, unless
+// their tag has the `required` or `default=` option.
+func UnmarshalUnitySave(u *UnitySave, i interface{}) error {
+	_, err := unmarshalUnitySave(u, i)
+	return err
+}
+
+// unmarshalUnitySave implements UnmarshalUnitySave and additionally returns the keys which are missing from the
+// document and have no default value.
+func unmarshalUnitySave(u *UnitySave, i interface{}) ([]string, error) {
+	taggedFields, err := scanStructTags(i, "vs_save")
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for key, field := range taggedFields {
+		data, ok := u.Fields[unityKey(key)]
+		if !ok {
+			if err := field.unmarshalMissing(key, "save data"); err != nil {
+				return nil, err
+			}
+			if !field.options.hasDefault {
+				missing = append(missing, key)
+			}
+			continue
+		}
+		if err := unmarshalField(data, field.value); err != nil {
+			return nil, fmt.Errorf("could not unmarshal %s: %w", key, err)
+		}
+	}
+
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// MarshalUnitySave serializes the fields tagged with `vs_save` in the provided interface into the UnitySave. Fields of
+// the document which are not part of the interface are kept untouched, just like the fields skipped by the `readonly`
+// and `omitempty` options.
+func MarshalUnitySave(i interface{}, u *UnitySave) error {
+	taggedFields, err := scanStructTags(i, "vs_save")
+	if err != nil {
+		return err
+	}
+
+	for key, field := range taggedFields {
+		if field.skipMarshal() {
+			continue
+		}
+		data, err := marshalField(field.value)
+		if err != nil {
+			return err
+		}
+		u.Fields[unityKey(key)] = data
+	}
+
+	return nil
+}
+
+// OpenUnitySaveFile opens the Unity-era save file located at the provided path and returns it wrapped in a SaveFile
+// instance, as well as the underlying UnitySave which is needed to store it again.
+func OpenUnitySaveFile(path string) (*SaveFile, *UnitySave, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	doc, err := ParseUnitySave(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	save := new(SaveFile)
+	save.missing, err = unmarshalUnitySave(doc, save)
+	return save, doc, err
+}
+
+// StoreUnitySaveFile writes the SaveFile into the UnitySave, which you can obtain by using OpenUnitySaveFile or
+// NewUnitySave, and stores it at the provided path. The checksum of the document is kept untouched, so it no longer
+// matches once fields were changed: recomputing it requires the algorithm of the game, which is still unknown.
+func StoreUnitySaveFile(save *SaveFile, doc *UnitySave, path string) error {
+	if err := MarshalUnitySave(save, doc); err != nil {
+		return err
+	}
+	return storeUnitySave(doc, path)
+}
+
+// storeUnitySave serializes the UnitySave and replaces the file at the provided path with it.
+func storeUnitySave(doc *UnitySave, path string) error {
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failing write never leaves a half-written save behind.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
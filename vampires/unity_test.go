@@ -0,0 +1,53 @@
+package vampires
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_UnitySaveRoundTrip(t *testing.T) {
+	save := &SaveFile{
+		Coins:            1337,
+		UnlockedWeapons:  []string{"WHIP", "KNIFE"},
+		KillCount:        map[string]int32{"BAT1": 42},
+		Language:         "en",
+		LifetimeSurvived: 900,
+	}
+
+	// Read-only fields are not written, so their values in the document are kept.
+	doc := NewUnitySave()
+	doc.Fields["UnknownField"] = json.RawMessage(`{"kept":true}`)
+	doc.Fields["LifetimeSurvived"] = json.RawMessage(`900`)
+	assert.NoError(t, MarshalUnitySave(save, doc))
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	parsed, err := ParseUnitySave(&buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kept":true}`, string(parsed.Fields["UnknownField"]))
+
+	actual := new(SaveFile)
+	assert.NoError(t, UnmarshalUnitySave(parsed, actual))
+	assert.Equal(t, save, actual)
+}
+
+func Test_UnitySaveKeepsChecksum(t *testing.T) {
+	parsed, err := ParseUnitySave(strings.NewReader(`{"Coins":100,"checksum":"deadbeef"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "deadbeef", parsed.Checksum())
+
+	assert.NoError(t, MarshalUnitySave(&SaveFile{Coins: 999}, parsed))
+	var buf bytes.Buffer
+	_, err = parsed.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	stored, err := ParseUnitySave(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "deadbeef", stored.Checksum())
+	assert.JSONEq(t, `999`, string(stored.Fields["Coins"]))
+}